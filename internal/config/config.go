@@ -39,6 +39,28 @@ type Config struct {
 
 	// Metrics configuration
 	MetricsEnabled bool `env:"METRICS_ENABLED" envDefault:"true"` // Enabled by default
+
+	// Plugins configuration
+	PluginsDir string `env:"PLUGINS_DIR" envDefault:""` // Empty = plugins disabled
+
+	// Secrets configuration
+	SecretsAllowedEnv []string `env:"SECRETS_ALLOWED_ENV" envDefault:"ONEOFF_SECRET_*" envSeparator:","` // Server variables env: secret refs may name
+	SecretsDir        string   `env:"SECRETS_DIR" envDefault:""`                                         // Empty = file: secret refs disabled
+
+	// Files job configuration
+	FilesAllowedRoots []string `env:"FILES_ALLOWED_ROOTS" envSeparator:","` // Empty = files jobs disabled
+
+	// S3 job configuration
+	S3AmbientCredentials bool `env:"S3_AMBIENT_CREDENTIALS" envDefault:"false"` // Let jobs without credential secrets use the server's AWS credentials
+
+	// Backup job configuration
+	BackupUploadTargets []string `env:"BACKUP_UPLOAD_TARGETS" envSeparator:","` // endpoint/bucket pairs; empty = backup uploads disabled
+
+	// Shell job configuration
+	ShellAllowedInterpreters []string `env:"SHELL_ALLOWED_INTERPRETERS" envDefault:"sh,bash,python3,node" envSeparator:","`
+	ShellInheritEnv          []string `env:"SHELL_INHERIT_ENV" envDefault:"LANG,LC_*,TZ" envSeparator:","` // Server variables passed to shell jobs
+	ShellAllowedRunAs        []string `env:"SHELL_ALLOWED_RUN_AS" envDefault:"" envSeparator:","`          // Empty = run_as disabled
+	ShellCgroupParent        string   `env:"SHELL_CGROUP_PARENT" envDefault:""`                            // Empty = no cgroup caps
 }
 
 // Load loads configuration from environment variables
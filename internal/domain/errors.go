@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// Generic errors
@@ -12,6 +15,7 @@ var (
 	ErrInvalidJobConfig = errors.New("invalid job configuration")
 	ErrJobAlreadyExists = errors.New("job already exists")
 	ErrJobNotScheduled  = errors.New("job is not in scheduled status")
+	ErrRunAlreadyQueued = errors.New("job already has a run waiting to start")
 
 	// Execution errors
 	ErrExecutionNotFound  = errors.New("execution not found")
@@ -45,3 +49,18 @@ var (
 	ErrConfigError      = errors.New("configuration error")
 	ErrWorkerPoolClosed = errors.New("worker pool is closed")
 )
+
+// PolicyViolation is returned when a config is well-formed but forbidden by the server's policy
+type PolicyViolation struct {
+	Reason string
+}
+
+// Error returns the reason of the violation
+func (e *PolicyViolation) Error() string {
+	return e.Reason
+}
+
+// NewPolicyViolation formats a policy violation
+func NewPolicyViolation(format string, args ...any) error {
+	return &PolicyViolation{Reason: fmt.Sprintf(format, args...)}
+}
@@ -3,6 +3,8 @@ package domain
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"time"
 )
 
 // JobExecutor defines the interface that all job types must implement
@@ -20,11 +22,88 @@ type JobExecutor interface {
 	Description() string
 }
 
+// ContextValidator is implemented by executors whose validation calls out to other processes or
+// services, so that it stops with the caller's context
+type ContextValidator interface {
+	// ValidateContext validates the job configuration until ctx ends
+	ValidateContext(ctx context.Context) error
+}
+
+// ValidateExecutor validates an executor, passing ctx to those that accept it
+func ValidateExecutor(ctx context.Context, executor JobExecutor) error {
+	if v, ok := executor.(ContextValidator); ok {
+		return v.ValidateContext(ctx)
+	}
+	return executor.Validate()
+}
+
+// DryRunner is implemented by executors that can check a job against its target without its side effects
+type DryRunner interface {
+	// DryRun performs the safe checks and reports them like an execution
+	DryRun(ctx context.Context) (*ExecutionResult, error)
+}
+
 // ExecutionResult represents the result of a job execution
 type ExecutionResult struct {
 	Output   string
 	ExitCode int
 	Error    string
+
+	// RescheduleAfter asks the worker pool to run the job again after this delay
+	// instead of completing the execution. The worker is released in the meantime.
+	RescheduleAfter time.Duration
+
+	// Steps holds the per-step breakdown of multi-step jobs
+	Steps []ExecutionStep
+
+	// EnvKeys lists the names of the environment variables the job ran with
+	EnvKeys []string
+
+	// Usage holds the resources consumed by the job's processes, when the executor measures them
+	Usage *ResourceUsage
+
+	// Outputs holds the named values the job emitted
+	Outputs map[string]string
+}
+
+type firstAttemptKey struct{}
+
+// WithFirstAttempt stores the start time of the first run of a rescheduled execution
+func WithFirstAttempt(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, firstAttemptKey{}, t)
+}
+
+// FirstAttemptFromContext returns the start time of the first run of the execution
+func FirstAttemptFromContext(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(firstAttemptKey{}).(time.Time)
+	return t, ok
+}
+
+type projectEnvKey struct{}
+
+// WithProjectEnv stores the environment variables injected by the job's project
+func WithProjectEnv(ctx context.Context, env map[string]string) context.Context {
+	return context.WithValue(ctx, projectEnvKey{}, env)
+}
+
+// ProjectEnvFromContext returns the environment variables injected by the job's project
+func ProjectEnvFromContext(ctx context.Context) map[string]string {
+	env, _ := ctx.Value(projectEnvKey{}).(map[string]string)
+	return env
+}
+
+// IsValidEnvName reports whether name is a valid POSIX environment variable name
+func IsValidEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
 }
 
 // JobFactory is a function that creates a JobExecutor from a config
@@ -33,12 +112,21 @@ type JobFactory func(config string) (JobExecutor, error)
 // JobRegistry manages registered job types
 type JobRegistry struct {
 	factories map[string]JobFactory
+	types     map[string]JobTypeInfo
+}
+
+// JobTypeInfo describes a job type and the schema of its config
+type JobTypeInfo struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema,omitempty"`
 }
 
 // NewJobRegistry creates a new job registry
 func NewJobRegistry() *JobRegistry {
 	return &JobRegistry{
 		factories: make(map[string]JobFactory),
+		types:     make(map[string]JobTypeInfo),
 	}
 }
 
@@ -47,6 +135,11 @@ func (r *JobRegistry) Register(jobType string, factory JobFactory) {
 	r.factories[jobType] = factory
 }
 
+// Describe sets the description and config schema of a registered job type
+func (r *JobRegistry) Describe(jobType, description string, schema *Schema) {
+	r.types[jobType] = JobTypeInfo{Name: jobType, Description: description, Schema: schema}
+}
+
 // Create creates a JobExecutor for the given job type and config
 func (r *JobRegistry) Create(jobType string, config string) (JobExecutor, error) {
 	factory, exists := r.factories[jobType]
@@ -56,6 +149,30 @@ func (r *JobRegistry) Create(jobType string, config string) (JobExecutor, error)
 	return factory(config)
 }
 
+// Validate checks a config against the job type's schema, then lets the executor validate it.
+// Templated configs must be rendered first, e.g. with SampleParams and secrets masked.
+func (r *JobRegistry) Validate(ctx context.Context, jobType string, config string) error {
+	if _, exists := r.factories[jobType]; !exists {
+		return &ValidationError{Errors: []FieldError{{Field: "type", Message: ErrJobTypeNotFound.Error()}}}
+	}
+
+	if schema := r.types[jobType].Schema; schema != nil {
+		if errs := schema.ValidateJSON(config, "config"); len(errs) > 0 {
+			return &ValidationError{Errors: errs}
+		}
+	}
+
+	executor, err := r.Create(jobType, config)
+	if err != nil {
+		return &ValidationError{Errors: []FieldError{{Field: "config", Message: err.Error()}}}
+	}
+	if err := ValidateExecutor(ctx, executor); err != nil {
+		var violation *PolicyViolation
+		return &ValidationError{Errors: []FieldError{{Field: "config", Message: err.Error(), Policy: errors.As(err, &violation)}}}
+	}
+	return nil
+}
+
 // ListTypes returns all registered job types
 func (r *JobRegistry) ListTypes() []string {
 	types := make([]string, 0, len(r.factories))
@@ -65,13 +182,99 @@ func (r *JobRegistry) ListTypes() []string {
 	return types
 }
 
+// TypeInfo returns the description and schema of a registered job type
+func (r *JobRegistry) TypeInfo(jobType string) JobTypeInfo {
+	if info, ok := r.types[jobType]; ok {
+		return info
+	}
+	return JobTypeInfo{Name: jobType}
+}
+
 // HTTPJobConfig represents configuration for HTTP request jobs
 type HTTPJobConfig struct {
-	URL     string            `json:"url"`
-	Method  string            `json:"method"`
-	Headers map[string]string `json:"headers,omitempty"`
-	Body    string            `json:"body,omitempty"`
-	Timeout int               `json:"timeout,omitempty"` // seconds
+	URL        string            `json:"url" schema:"required"`
+	Method     string            `json:"method"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	Timeout    int               `json:"timeout,omitempty"`    // seconds
+	Assertions *HTTPAssertions   `json:"assertions,omitempty"` // Success criteria, replaces the default status < 400 check
+	Retry      *HTTPRetryConfig  `json:"retry,omitempty"`      // defaults to 3 retries on network errors for idempotent methods
+	Poll       *HTTPPollConfig   `json:"poll,omitempty"`       // Poll a status URL until an asynchronous operation completes
+	Outputs    map[string]string `json:"outputs,omitempty"`    // Output names mapped to JSONPath expressions evaluated against the response body
+
+	Auth               *HTTPAuthConfig `json:"auth,omitempty"`
+	ClientCert         string          `json:"client_cert,omitempty"`          // PEM-encoded client certificate (mTLS)
+	ClientKeySecret    string          `json:"client_key_secret,omitempty"`    // Secret reference holding the PEM client key
+	CACert             string          `json:"ca_cert,omitempty"`              // PEM-encoded CA bundle
+	InsecureSkipVerify bool            `json:"insecure_skip_verify,omitempty"` // Skip server certificate verification
+	MaxRedirects       *int            `json:"max_redirects,omitempty"`        // defaults to 10, 0 disables redirects
+	SameHostRedirects  bool            `json:"same_host_redirects,omitempty"`  // Only follow redirects to the same host
+	Proxy              string          `json:"proxy,omitempty"`                // Proxy URL, e.g. http://proxy.internal:3128
+}
+
+// HTTPAuthConfig configures built-in authentication for HTTP jobs.
+// Credentials are secret references (env:NAME or file:name), never inline values.
+type HTTPAuthConfig struct {
+	Type string `json:"type" schema:"required,enum=basic|bearer|oauth2|aws_sigv4"` // basic, bearer, oauth2, aws_sigv4
+
+	// basic
+	Username       string `json:"username,omitempty"`
+	PasswordSecret string `json:"password_secret,omitempty"`
+
+	// bearer
+	TokenSecret string `json:"token_secret,omitempty"`
+
+	// oauth2 (client credentials grant)
+	TokenURL           string   `json:"token_url,omitempty"`
+	ClientID           string   `json:"client_id,omitempty"`
+	ClientSecretSecret string   `json:"client_secret_secret,omitempty"`
+	Scopes             []string `json:"scopes,omitempty"`
+	Audience           string   `json:"audience,omitempty"`
+
+	// aws_sigv4
+	Region                string `json:"region,omitempty"`
+	Service               string `json:"service,omitempty"`
+	AccessKeyIDSecret     string `json:"access_key_id_secret,omitempty"`
+	SecretAccessKeySecret string `json:"secret_access_key_secret,omitempty"`
+	SessionTokenSecret    string `json:"session_token_secret,omitempty"`
+}
+
+// HTTPRetryConfig controls how failed HTTP requests are retried
+type HTTPRetryConfig struct {
+	Count              int      `json:"count"`                                             // Retries after the first attempt, 0 disables retries
+	Backoff            string   `json:"backoff,omitempty" schema:"enum=fixed|exponential"` // fixed (default) or exponential
+	IntervalMs         int64    `json:"interval_ms,omitempty"`                             // Delay before the first retry, defaults to 2000
+	MaxIntervalMs      int64    `json:"max_interval_ms,omitempty"`                         // Upper bound for exponential backoff, defaults to 30000
+	StatusCodes        []string `json:"status_codes,omitempty"`                            // Response codes to retry, e.g. ["429", "5xx"]
+	NetworkErrors      *bool    `json:"network_errors,omitempty"`                          // Retry on connection errors, defaults to true
+	AllowNonIdempotent bool     `json:"allow_non_idempotent,omitempty"`                    // Also retry POST and PATCH requests
+}
+
+// HTTPPollConfig configures polling of asynchronous HTTP APIs that answer with a status URL.
+// Each condition matches when all of its assertions pass.
+type HTTPPollConfig struct {
+	StatusURLHeader string          `json:"status_url_header,omitempty"` // Header holding the status URL, defaults to Location
+	StatusURLPath   string          `json:"status_url_path,omitempty"`   // JSONPath to the status URL in the response body
+	Interval        int             `json:"interval,omitempty"`          // seconds between polls, defaults to 5
+	Deadline        int             `json:"deadline,omitempty"`          // seconds to keep polling, defaults to 3600
+	SuccessWhen     *HTTPAssertions `json:"success_when,omitempty"`      // defaults to any 2xx status except 202
+	FailureWhen     *HTTPAssertions `json:"failure_when,omitempty"`      // defaults to any status >= 400
+}
+
+// HTTPAssertions defines the success criteria of an HTTP job
+type HTTPAssertions struct {
+	StatusCodes  []string            `json:"status_codes,omitempty"`   // Exact codes ("204"), classes ("2xx") or ranges ("200-299")
+	BodyRegex    string              `json:"body_regex,omitempty"`     // Regular expression the body must match
+	JSONPath     []JSONPathAssertion `json:"json_path,omitempty"`      // Checks against the JSON response body
+	Headers      map[string]string   `json:"headers,omitempty"`        // Required headers, an empty value only checks presence
+	MaxLatencyMs int64               `json:"max_latency_ms,omitempty"` // Maximum response time
+}
+
+// JSONPathAssertion checks a value extracted from a JSON response body
+type JSONPathAssertion struct {
+	Path   string          `json:"path" schema:"required"` // e.g. $.data.items[0].status
+	Equals json.RawMessage `json:"equals,omitempty"`       // Expected JSON value
+	Exists *bool           `json:"exists,omitempty"`       // Whether the path must (or must not) exist
 }
 
 // ParseHTTPJobConfig parses HTTP job configuration from JSON
@@ -91,6 +294,34 @@ type ShellJobConfig struct {
 	WorkDir string            `json:"workdir,omitempty"`
 	Timeout int               `json:"timeout,omitempty"` // seconds
 	IsPath  bool              `json:"is_path,omitempty"` // true if script is a file path
+	Steps   []ShellStep       `json:"steps,omitempty"`   // Run these commands in order instead of script
+
+	// Interpreter is sh (default), bash, python3, node or an absolute path with arguments,
+	// e.g. "/usr/bin/perl -w"; it must be allowed by the server's SHELL_ALLOWED_INTERPRETERS
+	Interpreter string `json:"interpreter,omitempty"`
+
+	RunAs  string       `json:"run_as,omitempty"` // User name or uid[:gid] listed in SHELL_ALLOWED_RUN_AS; requires the server to run as root
+	Limits *ShellLimits `json:"limits,omitempty"` // Linux only
+}
+
+// ShellLimits represents resource limits applied to the processes of a shell job
+type ShellLimits struct {
+	CPUSeconds     uint64 `json:"cpu_seconds,omitempty"`      // RLIMIT_CPU
+	AddressSpaceMB uint64 `json:"address_space_mb,omitempty"` // RLIMIT_AS
+	OpenFiles      uint64 `json:"open_files,omitempty"`       // RLIMIT_NOFILE
+	Processes      uint64 `json:"processes,omitempty"`        // cgroup pids.max, or RLIMIT_NPROC without a cgroup
+
+	// Caps enforced through a cgroup v2 sub-group; they require SHELL_CGROUP_PARENT
+	MemoryMB uint64  `json:"memory_mb,omitempty"` // memory.max
+	CPUs     float64 `json:"cpus,omitempty"`      // cpu.max, e.g. 0.5 for half a core
+}
+
+// ShellStep is one named command of a multi-step shell job
+type ShellStep struct {
+	Name            string `json:"name" schema:"required"`
+	Script          string `json:"script" schema:"required"`
+	ContinueOnError bool   `json:"continue_on_error,omitempty"` // A failure does not stop or fail the job
+	Timeout         int    `json:"timeout,omitempty"`           // seconds
 }
 
 // ParseShellJobConfig parses shell job configuration from JSON
@@ -104,13 +335,22 @@ func ParseShellJobConfig(config string) (*ShellJobConfig, error) {
 
 // DockerJobConfig represents configuration for Docker container jobs
 type DockerJobConfig struct {
-	Image      string            `json:"image"`
+	Image      string            `json:"image" schema:"required"`
 	Command    []string          `json:"command,omitempty"`
 	Env        map[string]string `json:"env,omitempty"`
 	Volumes    map[string]string `json:"volumes,omitempty"` // host:container
 	WorkDir    string            `json:"workdir,omitempty"`
 	AutoRemove bool              `json:"auto_remove"`
 	Timeout    int               `json:"timeout,omitempty"` // seconds
+	Steps      []DockerStep      `json:"steps,omitempty"`   // Run these commands in order instead of command
+}
+
+// DockerStep is one named command of a multi-step Docker job
+type DockerStep struct {
+	Name            string   `json:"name" schema:"required"`
+	Command         []string `json:"command" schema:"required"`
+	ContinueOnError bool     `json:"continue_on_error,omitempty"` // A failure does not stop or fail the job
+	Timeout         int      `json:"timeout,omitempty"`           // seconds
 }
 
 // ParseDockerJobConfig parses Docker job configuration from JSON
@@ -121,3 +361,290 @@ func ParseDockerJobConfig(config string) (*DockerJobConfig, error) {
 	}
 	return &cfg, nil
 }
+
+// GRPCJobConfig represents configuration for gRPC unary call jobs
+type GRPCJobConfig struct {
+	Address            string            `json:"address" schema:"required"`      // host:port
+	Method             string            `json:"method"`                         // package.Service/Method
+	Request            string            `json:"request,omitempty"`              // JSON-encoded request message
+	Metadata           map[string]string `json:"metadata,omitempty"`             // Outgoing metadata headers
+	DescriptorSet      string            `json:"descriptor_set,omitempty"`       // Base64-encoded FileDescriptorSet (reflection is used when empty)
+	DescriptorSetFile  string            `json:"descriptor_set_file,omitempty"`  // Path to a FileDescriptorSet file inside the allowed roots
+	TLS                bool              `json:"tls,omitempty"`                  // Use TLS transport
+	CACert             string            `json:"ca_cert,omitempty"`              // PEM-encoded CA bundle
+	ClientCert         string            `json:"client_cert,omitempty"`          // PEM-encoded client certificate (mTLS)
+	ClientKeySecret    string            `json:"client_key_secret,omitempty"`    // Secret reference holding the PEM client key (mTLS)
+	ServerName         string            `json:"server_name,omitempty"`          // TLS server name override
+	InsecureSkipVerify bool              `json:"insecure_skip_verify,omitempty"` // Skip server certificate verification
+	Timeout            int               `json:"timeout,omitempty"`              // seconds, used as the call deadline
+}
+
+// ParseGRPCJobConfig parses gRPC job configuration from JSON
+func ParseGRPCJobConfig(config string) (*GRPCJobConfig, error) {
+	var cfg GRPCJobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SSHJobConfig represents configuration for remote commands over SSH
+type SSHJobConfig struct {
+	Host                  string            `json:"host" schema:"required"`
+	Port                  int               `json:"port,omitempty" schema:"max=65535"` // defaults to 22
+	User                  string            `json:"user" schema:"required"`
+	PrivateKeySecret      string            `json:"private_key_secret,omitempty"` // Secret reference holding a PEM private key
+	PassphraseSecret      string            `json:"passphrase_secret,omitempty"`  // Secret reference holding the key passphrase
+	PasswordSecret        string            `json:"password_secret,omitempty"`    // Secret reference holding the password
+	KnownHosts            string            `json:"known_hosts,omitempty"`        // known_hosts content
+	KnownHostsFile        string            `json:"known_hosts_file,omitempty"`   // defaults to ~/.ssh/known_hosts
+	InsecureIgnoreHostKey bool              `json:"insecure_ignore_host_key,omitempty"`
+	Command               string            `json:"command,omitempty"` // Command to run remotely
+	Script                string            `json:"script,omitempty"`  // Script uploaded and run with /bin/sh
+	Args                  []string          `json:"args,omitempty"`    // Positional arguments for the script
+	Env                   map[string]string `json:"env,omitempty"`
+	Timeout               int               `json:"timeout,omitempty"` // seconds
+}
+
+// ParseSSHJobConfig parses SSH job configuration from JSON
+func ParseSSHJobConfig(config string) (*SSHJobConfig, error) {
+	var cfg SSHJobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// WasmJobConfig represents configuration for sandboxed WebAssembly (WASI) jobs
+type WasmJobConfig struct {
+	Module        string            `json:"module,omitempty"`                            // Base64-encoded WASI module
+	ModuleURL     string            `json:"module_url,omitempty"`                        // URL to fetch the module from
+	SHA256        string            `json:"sha256,omitempty"`                            // Expected module checksum (required with module_url)
+	Args          []string          `json:"args,omitempty"`                              // Arguments passed to the module (argv[1:])
+	Env           map[string]string `json:"env,omitempty"`                               // Environment visible to the module
+	MemoryLimitMB int               `json:"memory_limit_mb,omitempty" schema:"max=4096"` // Linear memory cap, defaults to 64
+	CallBudget    int64             `json:"call_budget,omitempty"`                       // Maximum number of function calls (0 = unlimited), not a CPU bound
+	Fuel          int64             `json:"fuel,omitempty"`                              // Deprecated: use call_budget
+	Timeout       int               `json:"timeout,omitempty" schema:"max=3600"`         // seconds, defaults to 300
+}
+
+// ParseWasmJobConfig parses WebAssembly job configuration from JSON
+func ParseWasmJobConfig(config string) (*WasmJobConfig, error) {
+	var cfg WasmJobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.CallBudget == 0 {
+		cfg.CallBudget = cfg.Fuel
+	}
+	return &cfg, nil
+}
+
+// SensorJobConfig represents configuration for sensor jobs that wait for a condition
+type SensorJobConfig struct {
+	Kind           string `json:"kind" schema:"required,enum=file|tcp|http|sqlite"` // file, tcp, http, sqlite
+	Path           string `json:"path,omitempty"`                                   // file: path or glob, sqlite: database file
+	Address        string `json:"address,omitempty"`                                // tcp: host:port
+	URL            string `json:"url,omitempty"`                                    // http: endpoint to check
+	ExpectedStatus int    `json:"expected_status,omitempty"`                        // http: defaults to 200
+	Query          string `json:"query,omitempty"`                                  // sqlite: query that must return at least one row
+	PokeInterval   int    `json:"poke_interval,omitempty"`                          // seconds between pokes, defaults to 30
+	Timeout        int    `json:"timeout,omitempty"`                                // seconds to wait in total, defaults to 3600
+	SoftFail       bool   `json:"soft_fail,omitempty"`                              // Succeed instead of failing when the timeout is reached
+}
+
+// ParseSensorJobConfig parses sensor job configuration from JSON
+func ParseSensorJobConfig(config string) (*SensorJobConfig, error) {
+	var cfg SensorJobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// CheckJobConfig represents configuration for TLS certificate and DNS checks
+type CheckJobConfig struct {
+	TLS     *TLSCheckConfig `json:"tls,omitempty"`
+	DNS     *DNSCheckConfig `json:"dns,omitempty"`
+	Timeout int             `json:"timeout,omitempty"` // seconds
+}
+
+// TLSCheckConfig verifies the certificate served by a TLS endpoint
+type TLSCheckConfig struct {
+	Address       string   `json:"address" schema:"required"`                           // host or host:port (defaults to port 443)
+	ServerName    string   `json:"server_name,omitempty"`                               // SNI and hostname to verify, defaults to the host
+	MinDaysValid  int      `json:"min_days_valid,omitempty"`                            // Fail if the certificate expires sooner
+	ExpectedNames []string `json:"expected_names,omitempty"`                            // Names the certificate must cover
+	MinVersion    string   `json:"min_version,omitempty" schema:"enum=1.0|1.1|1.2|1.3"` // Minimum negotiated protocol: 1.0, 1.1, 1.2 or 1.3
+	CACert        string   `json:"ca_cert,omitempty"`                                   // PEM-encoded roots, defaults to the system pool
+}
+
+// DNSCheckConfig resolves a record and compares it to expected values
+type DNSCheckConfig struct {
+	Name       string   `json:"name" schema:"required"`
+	RecordType string   `json:"record_type,omitempty"`                        // A (default), AAAA, CNAME, MX, TXT or NS
+	Resolver   string   `json:"resolver,omitempty"`                           // host:port of the DNS server, defaults to the system resolver
+	Expected   []string `json:"expected,omitempty"`                           // Expected values, e.g. ["192.0.2.1"] or ["10 mail.example.com"]
+	Match      string   `json:"match,omitempty" schema:"enum=exact|contains"` // exact (default) or contains
+}
+
+// ParseCheckJobConfig parses check job configuration from JSON
+func ParseCheckJobConfig(config string) (*CheckJobConfig, error) {
+	var cfg CheckJobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// PublishJobConfig represents configuration for publishing a message to a broker
+type PublishJobConfig struct {
+	Broker         string            `json:"broker" schema:"required,enum=nats|amqp"` // nats or amqp
+	URL            string            `json:"url" schema:"required"`                   // e.g. nats://localhost:4222 or amqp://localhost:5672/vhost
+	Username       string            `json:"username,omitempty"`                      // Overrides the user in the URL
+	PasswordSecret string            `json:"password_secret,omitempty"`               // Secret reference (env:NAME or file:name)
+	TokenSecret    string            `json:"token_secret,omitempty"`                  // NATS token, secret reference
+	Payload        string            `json:"payload"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Timeout        int               `json:"timeout,omitempty"` // seconds
+
+	// NATS
+	Subject   string `json:"subject,omitempty"`
+	JetStream bool   `json:"jetstream,omitempty"` // Publish to JetStream and wait for the stream ack
+
+	// AMQP 0-9-1
+	Exchange   string          `json:"exchange,omitempty"` // Empty for the default exchange
+	RoutingKey string          `json:"routing_key,omitempty"`
+	Mandatory  bool            `json:"mandatory,omitempty"` // Fail if the message cannot be routed to a queue
+	Confirm    *bool           `json:"confirm,omitempty"`   // Wait for publisher confirms, defaults to true
+	Properties *AMQPProperties `json:"properties,omitempty"`
+}
+
+// AMQPProperties are the AMQP message properties
+type AMQPProperties struct {
+	ContentType   string `json:"content_type,omitempty"`
+	Persistent    bool   `json:"persistent,omitempty"`
+	Priority      uint8  `json:"priority,omitempty" schema:"max=9"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	ReplyTo       string `json:"reply_to,omitempty"`
+	Expiration    string `json:"expiration,omitempty"` // milliseconds, as a string
+	MessageID     string `json:"message_id,omitempty"`
+	Type          string `json:"type,omitempty"`
+	AppID         string `json:"app_id,omitempty"`
+}
+
+// ParsePublishJobConfig parses publish job configuration from JSON
+func ParsePublishJobConfig(config string) (*PublishJobConfig, error) {
+	var cfg PublishJobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// S3JobConfig represents configuration for S3-compatible object storage jobs
+type S3JobConfig struct {
+	Operation string `json:"operation" schema:"required,enum=put|get|copy|delete|presign"` // put, get, copy, delete or presign
+	Endpoint  string `json:"endpoint,omitempty"`                                           // host[:port] of an S3-compatible store, defaults to s3.amazonaws.com
+	Region    string `json:"region,omitempty"`                                             // e.g. eu-west-1
+	Insecure  bool   `json:"insecure,omitempty"`                                           // Use plain HTTP, e.g. for a local MinIO
+	PathStyle bool   `json:"path_style,omitempty"`                                         // Force path-style addressing
+
+	// Credentials are secret references; the AWS environment and instance role are used when unset and S3_AMBIENT_CREDENTIALS allows it
+	AccessKeyIDSecret     string `json:"access_key_id_secret,omitempty"`
+	SecretAccessKeySecret string `json:"secret_access_key_secret,omitempty"`
+	SessionTokenSecret    string `json:"session_token_secret,omitempty"`
+
+	Bucket string `json:"bucket" schema:"required"`
+	Key    string `json:"key,omitempty"`
+
+	// put
+	Source      string `json:"source,omitempty"`       // Local file to upload, inside the allowed roots
+	SourceURL   string `json:"source_url,omitempty"`   // URL to stream into the bucket
+	ContentType string `json:"content_type,omitempty"` // Defaults to application/octet-stream
+	PartSizeMB  int    `json:"part_size_mb,omitempty"` // Multipart part size, defaults to 16
+
+	// get
+	Destination string `json:"destination,omitempty"` // Local file to write, inside the allowed roots
+
+	// copy
+	SourceBucket string `json:"source_bucket,omitempty"` // Defaults to bucket
+	SourceKey    string `json:"source_key,omitempty"`
+
+	// delete
+	Prefix           string `json:"prefix,omitempty"`
+	AllowEmptyPrefix bool   `json:"allow_empty_prefix,omitempty"` // Required to delete from the whole bucket
+	OlderThan        string `json:"older_than,omitempty"`         // Only delete objects older than this duration, e.g. 720h
+
+	// presign
+	Method    string `json:"method,omitempty"`     // GET (default) or PUT
+	ExpiresIn int    `json:"expires_in,omitempty"` // seconds, defaults to 3600
+
+	Timeout int `json:"timeout,omitempty"` // seconds
+}
+
+// ParseS3JobConfig parses S3 job configuration from JSON
+func ParseS3JobConfig(config string) (*S3JobConfig, error) {
+	var cfg S3JobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// FilesJobConfig represents configuration for declarative file operations
+type FilesJobConfig struct {
+	Operation   string `json:"operation" schema:"required,enum=copy|move|delete|archive|checksum"` // copy, move, delete, archive or checksum
+	Source      string `json:"source" schema:"required"`                                           // File or directory; directories are walked recursively
+	Destination string `json:"destination,omitempty"`                                              // copy/move: target directory, archive: archive file
+	Pattern     string `json:"pattern,omitempty"`                                                  // Only files whose name matches this glob, e.g. *.log
+	OlderThan   string `json:"older_than,omitempty"`                                               // Only files last modified before this duration ago, e.g. 168h
+	Format      string `json:"format,omitempty" schema:"enum=tar.gz|zip"`                          // archive: tar.gz (default) or zip
+	Algorithm   string `json:"algorithm,omitempty" schema:"enum=sha256|sha1|md5"`                  // checksum: sha256 (default), sha1 or md5
+	Overwrite   bool   `json:"overwrite,omitempty"`                                                // Replace existing destination files
+	DryRun      bool   `json:"dry_run,omitempty"`                                                  // Only report the affected files
+	Timeout     int    `json:"timeout,omitempty"`                                                  // seconds
+}
+
+// ParseFilesJobConfig parses files job configuration from JSON
+func ParseFilesJobConfig(config string) (*FilesJobConfig, error) {
+	var cfg FilesJobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// OneOffBackupJobConfig represents configuration for backups of the OneOff database
+type OneOffBackupJobConfig struct {
+	Directory string              `json:"directory" schema:"required"` // Local directory for snapshots
+	Compress  bool                `json:"compress,omitempty"`          // gzip the snapshot
+	Upload    *BackupUploadConfig `json:"upload,omitempty"`            // Also upload the snapshot to S3-compatible storage
+	KeepLast  int                 `json:"keep_last,omitempty"`         // Keep only the newest N backups, 0 keeps all
+	MaxAge    string              `json:"max_age,omitempty"`           // Remove backups older than this duration, e.g. 720h
+	Timeout   int                 `json:"timeout,omitempty"`           // seconds
+}
+
+// BackupUploadConfig represents the S3-compatible target for database backups
+type BackupUploadConfig struct {
+	Endpoint  string `json:"endpoint,omitempty"` // host[:port], defaults to s3.amazonaws.com
+	Region    string `json:"region,omitempty"`
+	Insecure  bool   `json:"insecure,omitempty"`
+	PathStyle bool   `json:"path_style,omitempty"`
+
+	AccessKeyIDSecret     string `json:"access_key_id_secret,omitempty"`
+	SecretAccessKeySecret string `json:"secret_access_key_secret,omitempty"`
+	SessionTokenSecret    string `json:"session_token_secret,omitempty"`
+
+	Bucket string `json:"bucket" schema:"required"`
+	Prefix string `json:"prefix,omitempty"` // e.g. oneoff/
+}
+
+// ParseOneOffBackupJobConfig parses backup job configuration from JSON
+func ParseOneOffBackupJobConfig(config string) (*OneOffBackupJobConfig, error) {
+	var cfg OneOffBackupJobConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
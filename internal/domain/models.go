@@ -23,46 +23,100 @@ const (
 	ExecutionStatusCancelled ExecutionStatus = "cancelled"
 )
 
+// StepStatus represents the status of a single step within an execution
+type StepStatus string
+
+const (
+	StepStatusCompleted StepStatus = "completed"
+	StepStatusFailed    StepStatus = "failed"
+	StepStatusCancelled StepStatus = "cancelled"
+	StepStatusSkipped   StepStatus = "skipped"
+)
+
 // Job represents a scheduled one-time job
 type Job struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Type        string    `json:"type"`
-	Config      string    `json:"config"` // JSON string for job-specific config
-	ScheduledAt time.Time `json:"scheduled_at"`
-	Priority    int       `json:"priority"` // 1-10
-	ProjectID   string    `json:"project_id"`
-	Timezone    string    `json:"timezone"`
-	Status      JobStatus `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Tags        []Tag     `json:"tags,omitempty"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Type        string         `json:"type"`
+	Config      string         `json:"config"` // JSON string for job-specific config
+	ScheduledAt time.Time      `json:"scheduled_at"`
+	Priority    int            `json:"priority"` // 1-10
+	ProjectID   string         `json:"project_id"`
+	Timezone    string         `json:"timezone"`
+	Status      JobStatus      `json:"status"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	Tags        []Tag          `json:"tags,omitempty"`
+	Parameters  []JobParameter `json:"parameters,omitempty"`
 }
 
 // JobExecution represents an execution instance of a job
 type JobExecution struct {
-	ID          string          `json:"id"`
-	JobID       string          `json:"job_id"`
-	StartedAt   time.Time       `json:"started_at"`
-	CompletedAt *time.Time      `json:"completed_at,omitempty"`
-	Status      ExecutionStatus `json:"status"`
-	Output      string          `json:"output,omitempty"`
-	ExitCode    *int            `json:"exit_code,omitempty"`
-	Error       string          `json:"error,omitempty"`
-	DurationMs  *int64          `json:"duration_ms,omitempty"`
-	CreatedAt   time.Time       `json:"created_at"`
+	ID          string            `json:"id"`
+	JobID       string            `json:"job_id"`
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+	Status      ExecutionStatus   `json:"status"`
+	Output      string            `json:"output,omitempty"`
+	ExitCode    *int              `json:"exit_code,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	DurationMs  *int64            `json:"duration_ms,omitempty"`
+	Steps       []ExecutionStep   `json:"steps,omitempty"`
+	EnvKeys     []string          `json:"env_keys,omitempty"`
+	Usage       *ResourceUsage    `json:"usage,omitempty"`
+	Params      map[string]any    `json:"params,omitempty"`
+	Outputs     map[string]string `json:"outputs,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// ExecutionStep records the result of one step of a multi-step job
+type ExecutionStep struct {
+	Name            string         `json:"name"`
+	Status          StepStatus     `json:"status"`
+	ExitCode        int            `json:"exit_code"`
+	Output          string         `json:"output,omitempty"`
+	Error           string         `json:"error,omitempty"`
+	DurationMs      int64          `json:"duration_ms"`
+	Usage           *ResourceUsage `json:"usage,omitempty"`
+	ContinueOnError bool           `json:"continue_on_error,omitempty"`
+}
+
+// ResourceUsage records the resources consumed by an execution
+type ResourceUsage struct {
+	CPUUserMs   int64  `json:"cpu_user_ms"`
+	CPUSystemMs int64  `json:"cpu_system_ms"`
+	MaxRSSKB    int64  `json:"max_rss_kb"`
+	ReadBytes   int64  `json:"read_bytes"`
+	WriteBytes  int64  `json:"write_bytes"`
+	ExitSignal  string `json:"exit_signal,omitempty"` // Signal that terminated the process, e.g. SIGKILL
+}
+
+// Add accumulates the usage of another run, keeping the highest peak memory and the latest signal
+func (u *ResourceUsage) Add(other *ResourceUsage) {
+	if other == nil {
+		return
+	}
+	u.CPUUserMs += other.CPUUserMs
+	u.CPUSystemMs += other.CPUSystemMs
+	u.MaxRSSKB = max(u.MaxRSSKB, other.MaxRSSKB)
+	u.ReadBytes += other.ReadBytes
+	u.WriteBytes += other.WriteBytes
+	if other.ExitSignal != "" {
+		u.ExitSignal = other.ExitSignal
+	}
 }
 
 // Project represents a project for organizing jobs
 type Project struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	Color       string    `json:"color,omitempty"`
-	Icon        string    `json:"icon,omitempty"`
-	IsArchived  bool      `json:"is_archived"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Color       string            `json:"color,omitempty"`
+	Icon        string            `json:"icon,omitempty"`
+	Env         map[string]string `json:"env,omitempty"` // Injected into the environment of the project's shell jobs
+	IsArchived  bool              `json:"is_archived"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
 }
 
 // Tag represents a tag for categorizing jobs
@@ -102,27 +156,62 @@ type SystemConfig struct {
 
 // CreateJobRequest represents a request to create a new job
 type CreateJobRequest struct {
-	Name        string   `json:"name"`
-	Type        string   `json:"type"`
-	Config      string   `json:"config"`
-	ScheduledAt string   `json:"scheduled_at,omitempty"`
-	Immediate   bool     `json:"immediate,omitempty"`
-	Priority    int      `json:"priority,omitempty"`
-	ProjectID   string   `json:"project_id,omitempty"`
-	Timezone    string   `json:"timezone,omitempty"`
-	TagIDs      []string `json:"tag_ids,omitempty"`
+	Name        string         `json:"name"`
+	Type        string         `json:"type"`
+	Config      string         `json:"config"`
+	ScheduledAt string         `json:"scheduled_at,omitempty"`
+	Immediate   bool           `json:"immediate,omitempty"`
+	Priority    int            `json:"priority,omitempty"`
+	ProjectID   string         `json:"project_id,omitempty"`
+	Timezone    string         `json:"timezone,omitempty"`
+	TagIDs      []string       `json:"tag_ids,omitempty"`
+	Parameters  []JobParameter `json:"parameters,omitempty"`
+}
+
+// ValidateJobRequest represents a request to check a job without creating it
+type ValidateJobRequest struct {
+	CreateJobRequest
+	Params map[string]any `json:"params,omitempty"`  // Sample parameter values for rendering the config
+	DryRun bool           `json:"dry_run,omitempty"` // Also run the job type's safe checks against its target
+}
+
+// JobValidation is the outcome of checking a job without creating it
+type JobValidation struct {
+	Valid          bool          `json:"valid"`
+	Errors         []FieldError  `json:"errors,omitempty"`
+	Violations     []string      `json:"violations,omitempty"` // Rejections by the server's policy
+	RenderedConfig string        `json:"rendered_config,omitempty"`
+	Description    string        `json:"description,omitempty"`
+	Schedule       *JobSchedule  `json:"schedule,omitempty"`
+	DryRun         *DryRunResult `json:"dry_run,omitempty"`
+}
+
+// JobSchedule is when a job would run, in UTC and in the job's timezone
+type JobSchedule struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+	Local       string    `json:"local"`
+	Timezone    string    `json:"timezone"`
+}
+
+// DryRunResult is the outcome of a job type's safe checks
+type DryRunResult struct {
+	Supported bool   `json:"supported"`
+	Passed    bool   `json:"passed"`
+	Output    string `json:"output,omitempty"`
+	Error     string `json:"error,omitempty"`
 }
 
 // UpdateJobRequest represents a request to update a job
 type UpdateJobRequest struct {
-	Name        *string  `json:"name,omitempty"`
-	Config      *string  `json:"config,omitempty"`
-	ScheduledAt *string  `json:"scheduled_at,omitempty"`
-	Priority    *int     `json:"priority,omitempty"`
-	ProjectID   *string  `json:"project_id,omitempty"`
-	Timezone    *string  `json:"timezone,omitempty"`
-	Status      *string  `json:"status,omitempty"`
-	TagIDs      []string `json:"tag_ids,omitempty"`
+	Name        *string        `json:"name,omitempty"`
+	Config      *string        `json:"config,omitempty"`
+	ScheduledAt *string        `json:"scheduled_at,omitempty"`
+	Priority    *int           `json:"priority,omitempty"`
+	ProjectID   *string        `json:"project_id,omitempty"`
+	Timezone    *string        `json:"timezone,omitempty"`
+	Status      *string        `json:"status,omitempty"`
+	TagIDs      []string       `json:"tag_ids,omitempty"`
+	Parameters  []JobParameter `json:"parameters,omitempty"` // Replaces the declared parameters when set
 }
 
 // JobFilter represents filters for querying jobs
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParameterType is the type of a job parameter's values
+type ParameterType string
+
+const (
+	ParameterTypeString  ParameterType = "string"
+	ParameterTypeNumber  ParameterType = "number"
+	ParameterTypeInteger ParameterType = "integer"
+	ParameterTypeBoolean ParameterType = "boolean"
+)
+
+// parameterNamePattern keeps parameter names usable as template fields and environment variables
+var parameterNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// JobParameter declares an input that callers provide when running a job
+type JobParameter struct {
+	Name        string        `json:"name"`
+	Type        ParameterType `json:"type"`
+	Default     any           `json:"default,omitempty"`
+	Required    bool          `json:"required,omitempty"`
+	Description string        `json:"description,omitempty"`
+}
+
+// ValidateParameters checks a job's parameter declarations
+func ValidateParameters(params []JobParameter) error {
+	// Names are compared upper-cased, as they are in the ONEOFF_PARAM_<NAME> variables
+	seen := make(map[string]string, len(params))
+	for i, param := range params {
+		if !parameterNamePattern.MatchString(param.Name) {
+			return fmt.Errorf("parameters[%d].name must be a letter or underscore followed by letters, digits or underscores: %q", i, param.Name)
+		}
+		if other, ok := seen[strings.ToUpper(param.Name)]; ok {
+			if other == param.Name {
+				return fmt.Errorf("duplicate parameter name: %s", param.Name)
+			}
+			return fmt.Errorf("parameter names %s and %s differ only by case", other, param.Name)
+		}
+		seen[strings.ToUpper(param.Name)] = param.Name
+
+		switch param.Type {
+		case ParameterTypeString, ParameterTypeNumber, ParameterTypeInteger, ParameterTypeBoolean:
+		default:
+			return fmt.Errorf("parameter %s: type must be string, number, integer or boolean", param.Name)
+		}
+		if param.Default != nil {
+			if _, err := param.convert(param.Default); err != nil {
+				return fmt.Errorf("parameter %s: invalid default: %w", param.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveParams checks the values given for a run against the job's parameters and fills in defaults
+func ResolveParams(params []JobParameter, values map[string]any) (map[string]any, error) {
+	for name := range values {
+		if !slices.ContainsFunc(params, func(p JobParameter) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("unknown parameter: %s", name)
+		}
+	}
+
+	resolved := make(map[string]any, len(params))
+	for _, param := range params {
+		value, ok := values[param.Name]
+		if !ok || value == nil {
+			if param.Default == nil {
+				if param.Required {
+					return nil, fmt.Errorf("missing required parameter: %s", param.Name)
+				}
+				continue
+			}
+			value = param.Default
+		}
+
+		converted, err := param.convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", param.Name, err)
+		}
+		resolved[param.Name] = converted
+	}
+	return resolved, nil
+}
+
+// sampleParamValues stand in for required parameters without a default when a config is validated
+var sampleParamValues = map[ParameterType]any{
+	ParameterTypeString:  "sample",
+	ParameterTypeNumber:  float64(1),
+	ParameterTypeInteger: float64(1),
+	ParameterTypeBoolean: false,
+}
+
+// SampleParams returns the parameter values a job's config is validated with before it runs: the
+// defaults, and a sample value of the right type for required parameters without one
+func SampleParams(params []JobParameter) map[string]any {
+	values := make(map[string]any)
+	for _, param := range params {
+		if param.Required && param.Default == nil {
+			values[param.Name] = sampleParamValues[param.Type]
+		}
+	}
+	resolved, err := ResolveParams(params, values)
+	if err != nil {
+		return map[string]any{}
+	}
+	return resolved
+}
+
+// convert checks a value against the parameter's type and normalizes its Go type
+func (p JobParameter) convert(value any) (any, error) {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", n)
+		}
+		value = f
+	}
+
+	switch p.Type {
+	case ParameterTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("expected a string")
+	case ParameterTypeNumber:
+		if f, ok := value.(float64); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("expected a number")
+	case ParameterTypeInteger:
+		if f, ok := value.(float64); ok && f == math.Trunc(f) && math.Abs(f) <= 1<<53 {
+			return int64(f), nil
+		}
+		return nil, fmt.Errorf("expected an integer")
+	case ParameterTypeBoolean:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected a boolean")
+	}
+	return nil, fmt.Errorf("unsupported type: %s", p.Type)
+}
+
+// paramsEnv returns parameter values as ONEOFF_PARAM_<NAME> environment variables
+func paramsEnv(params map[string]any) map[string]string {
+	env := make(map[string]string, len(params))
+	for name, value := range params {
+		env["ONEOFF_PARAM_"+strings.ToUpper(name)] = formatParam(value)
+	}
+	return env
+}
+
+// formatParam renders a parameter value as plain text
+func formatParam(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestValidateParametersCaseCollision(t *testing.T) {
+	err := ValidateParameters([]JobParameter{
+		{Name: "path", Type: ParameterTypeString},
+		{Name: "PATH", Type: ParameterTypeString},
+	})
+	if err == nil || !strings.Contains(err.Error(), "parameter names path and PATH differ only by case") {
+		t.Fatalf("expected a case collision error, got %v", err)
+	}
+}
+
+func TestShellQuoteTemplate(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no POSIX shell available")
+	}
+	data := &TemplateData{Params: map[string]any{"path": `it's $(id); "x"`, "days": int64(7)}}
+	rendered, err := RenderConfig(`{"script":"printf '%s|' {{ shellquote .Params.path }} {{ shellquote .Params.days }}"}`, data, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		Script string `json:"script"`
+	}
+	if err := json.Unmarshal([]byte(rendered), &config); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command("sh", "-c", config.Script).CombinedOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `it's $(id); "x"|7|` {
+		t.Fatalf("quoted values were not passed as single words: %q", out)
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// RunContextAware is implemented by executors that pass the identity of their execution on to
+// the processes they start or the services they call
+type RunContextAware interface {
+	JobExecutor
+
+	// SetRunContext is called by the worker pool before Execute
+	SetRunContext(rc *RunContext)
+}
+
+// RunContext identifies the execution a job runs for
+type RunContext struct {
+	JobID       string
+	ExecutionID string
+	Attempt     int // 1 for the first run, incremented when a rescheduled execution resumes
+	ScheduledAt time.Time
+	ProjectID   string
+	TraceParent string         // W3C traceparent, with the execution ID as trace ID
+	Params      map[string]any // Resolved values of the job's parameters
+}
+
+// NewRunContext creates the run context of an execution attempt with a fresh trace span
+func NewRunContext(job *Job, executionID string, attempt int, params map[string]any) *RunContext {
+	rc := &RunContext{
+		JobID:       job.ID,
+		ExecutionID: executionID,
+		Attempt:     attempt,
+		ScheduledAt: job.ScheduledAt,
+		ProjectID:   job.ProjectID,
+		Params:      params,
+	}
+
+	// Execution IDs are 32 hex characters, the same shape as a trace ID
+	spanID := make([]byte, 8)
+	if _, err := rand.Read(spanID); err == nil && len(executionID) == 32 {
+		if _, err := hex.DecodeString(executionID); err == nil {
+			rc.TraceParent = "00-" + executionID + "-" + hex.EncodeToString(spanID) + "-01"
+		}
+	}
+
+	return rc
+}
+
+// Env returns the run context as ONEOFF_* environment variables, including one ONEOFF_PARAM_<NAME>
+// variable per parameter
+func (rc *RunContext) Env() map[string]string {
+	env := paramsEnv(rc.Params)
+	env["ONEOFF_JOB_ID"] = rc.JobID
+	env["ONEOFF_EXECUTION_ID"] = rc.ExecutionID
+	env["ONEOFF_ATTEMPT"] = strconv.Itoa(rc.Attempt)
+	if !rc.ScheduledAt.IsZero() {
+		env["ONEOFF_SCHEDULED_AT"] = rc.ScheduledAt.UTC().Format(time.RFC3339)
+	}
+	if rc.ProjectID != "" {
+		env["ONEOFF_PROJECT"] = rc.ProjectID
+	}
+	if rc.TraceParent != "" {
+		env["TRACEPARENT"] = rc.TraceParent
+	}
+	return env
+}
+
+// Headers returns the run context as X-OneOff-* request headers and a traceparent header
+func (rc *RunContext) Headers() map[string]string {
+	headers := map[string]string{
+		"X-OneOff-Job-Id":       rc.JobID,
+		"X-OneOff-Execution-Id": rc.ExecutionID,
+		"X-OneOff-Attempt":      strconv.Itoa(rc.Attempt),
+	}
+	if !rc.ScheduledAt.IsZero() {
+		headers["X-OneOff-Scheduled-At"] = rc.ScheduledAt.UTC().Format(time.RFC3339)
+	}
+	if rc.ProjectID != "" {
+		headers["X-OneOff-Project"] = rc.ProjectID
+	}
+	if rc.TraceParent != "" {
+		headers["traceparent"] = rc.TraceParent
+	}
+	return headers
+}
@@ -0,0 +1,248 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema describing job configs
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // false or a *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// UnmarshalJSON decodes additionalProperties as either a boolean or a schema
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	var raw struct {
+		*plain
+		AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+	}
+	raw.plain = (*plain)(s)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	s.AdditionalProperties = nil
+	if len(raw.AdditionalProperties) == 0 {
+		return nil
+	}
+	var allowed bool
+	if err := json.Unmarshal(raw.AdditionalProperties, &allowed); err == nil {
+		s.AdditionalProperties = allowed
+		return nil
+	}
+	var additional Schema
+	if err := json.Unmarshal(raw.AdditionalProperties, &additional); err != nil {
+		return err
+	}
+	s.AdditionalProperties = &additional
+	return nil
+}
+
+// FieldError is a validation failure of one field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Policy  bool   `json:"policy,omitempty"` // Rejected by the server's policy rather than malformed
+}
+
+// ValidationError lists everything wrong with a job config
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// Error joins the field errors into one message
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// SchemaFor derives the schema of a config struct from its json tags. The schema tag adds constraints:
+// required, enum=a|b, min=N and max=N, separated by commas. Unknown fields are rejected.
+func SchemaFor(v any, description string) *Schema {
+	schema := schemaForType(reflect.TypeOf(v))
+	schema.Description = description
+	return schema
+}
+
+// schemaForType builds the schema of a Go type
+func schemaForType(t reflect.Type) *Schema {
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaForType(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema := &Schema{Type: "integer", Minimum: new(float64)}
+		if t.Bits() < 64 {
+			maximum := float64(uint64(1)<<t.Bits() - 1)
+			schema.Maximum = &maximum
+		}
+		return schema
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}, AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			property := schemaForType(field.Type)
+			for _, rule := range strings.Split(field.Tag.Get("schema"), ",") {
+				key, value, _ := strings.Cut(rule, "=")
+				switch key {
+				case "required":
+					schema.Required = append(schema.Required, name)
+				case "enum":
+					property.Enum = strings.Split(value, "|")
+				case "min":
+					minimum, _ := strconv.ParseFloat(value, 64)
+					property.Minimum = &minimum
+				case "max":
+					maximum, _ := strconv.ParseFloat(value, 64)
+					property.Maximum = &maximum
+				}
+			}
+			schema.Properties[name] = property
+		}
+		return schema
+	}
+	return &Schema{}
+}
+
+// ValidateJSON checks a JSON document against the schema, naming fields by their path from root
+func (s *Schema) ValidateJSON(data string, root string) []FieldError {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []FieldError{{Field: root, Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+
+	var errs []FieldError
+	s.validate(value, root, &errs)
+	return errs
+}
+
+// validate checks a decoded value, appending one error per offending field
+func (s *Schema) validate(value any, path string, errs *[]FieldError) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if value == nil {
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if str != "" && len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			fail("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		f, err := n.Float64()
+		if err != nil {
+			fail("must be a number")
+			return
+		}
+		if s.Type == "integer" && f != math.Trunc(f) {
+			fail("must be an integer")
+			return
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		for i, item := range items {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if v, ok := object[name]; !ok || v == nil || v == "" {
+				*errs = append(*errs, FieldError{Field: path + "." + name, Message: "is required"})
+			}
+		}
+
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				property.validate(object[name], path+"."+name, errs)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case *Schema:
+				additional.validate(object[name], path+"."+name, errs)
+			case bool:
+				if !additional {
+					*errs = append(*errs, FieldError{Field: path + "." + name, Message: "unknown field"})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// maxSecretSize caps the size of secret files
+const maxSecretSize = 1 << 20
+
+// SecretPolicy holds the admin-level restrictions on the secrets job configs may reference
+type SecretPolicy struct {
+	// AllowedEnv lists the server environment variables env: references may name; entries may be globs such as ONEOFF_SECRET_*
+	AllowedEnv []string
+	// Dir is the directory file: references are resolved in; empty disables file references
+	Dir string
+}
+
+var (
+	secretPolicyMu sync.RWMutex
+	secretPolicy   SecretPolicy
+)
+
+// SetSecretPolicy sets the restrictions applied by ResolveSecret. Until it is called no secret resolves.
+func SetSecretPolicy(policy SecretPolicy) {
+	secretPolicyMu.Lock()
+	defer secretPolicyMu.Unlock()
+	secretPolicy = policy
+}
+
+// currentSecretPolicy returns the restrictions applied by ResolveSecret
+func currentSecretPolicy() SecretPolicy {
+	secretPolicyMu.RLock()
+	defer secretPolicyMu.RUnlock()
+	return secretPolicy
+}
+
+// ResolveSecret resolves a secret reference so that credentials never live in job configs.
+// Supported references are "env:NAME" (an environment variable of the server process allowed by
+// SECRETS_ALLOWED_ENV) and "file:name" (a file in SECRETS_DIR, e.g. a mounted Docker or Kubernetes
+// secret). References that are not allowed, missing or unreadable all fail with the same error, so
+// that job authors cannot probe the server.
+func ResolveSecret(ref string) (string, error) {
+	if err := CheckSecretRef(ref); err != nil {
+		return "", err
+	}
+
+	scheme, name, _ := strings.Cut(ref, ":")
+	switch scheme {
+	case "env":
+		if value, exists := os.LookupEnv(name); exists {
+			return value, nil
+		}
+	case "file":
+		if value, err := readSecretFile(currentSecretPolicy().Dir, name); err == nil {
+			return value, nil
+		}
+	}
+	return "", secretUnavailable(ref)
+}
+
+// CheckSecretRef checks that a secret reference is well-formed and allowed by the policy, without resolving it
+func CheckSecretRef(ref string) error {
+	scheme, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid secret reference %q (use env:NAME or file:name)", ref)
+	}
+
+	policy := currentSecretPolicy()
+	switch scheme {
+	case "env":
+		for _, pattern := range policy.AllowedEnv {
+			if ok, _ := path.Match(pattern, name); ok {
+				return nil
+			}
+		}
+	case "file":
+		if policy.Dir != "" && secretFilePath(policy.Dir, name) != "" {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported secret reference scheme %q (use env or file)", scheme)
+	}
+	return secretUnavailable(ref)
+}
+
+// secretUnavailable is the error of every reference that cannot be resolved
+func secretUnavailable(ref string) error {
+	return NewPolicyViolation("secret %s is not available", ref)
+}
+
+// secretFilePath returns the path of a secret file inside dir, or "" when name points outside it.
+// Names are relative to dir; absolute paths are accepted when they lie inside it.
+func secretFilePath(dir, name string) string {
+	p := name
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(p))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.Clean(p)
+}
+
+// readSecretFile reads a regular secret file inside dir, resolving symlinks before the containment check
+func readSecretFile(dir, name string) (string, error) {
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(secretFilePath(dir, name))
+	if err != nil {
+		return "", err
+	}
+	if secretFilePath(root, resolved) == "" {
+		return "", fmt.Errorf("secret file resolves outside the secrets directory")
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxSecretSize {
+		return "", fmt.Errorf("secret file is not a regular file of at most %d bytes", maxSecretSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSecretSize))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
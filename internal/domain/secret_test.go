@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecretPolicy(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(t.TempDir(), "outside")
+	if err := os.WriteFile(outside, []byte("leaked"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ONEOFF_SECRET_TOKEN", "from-env")
+	t.Setenv("SERVER_ONLY", "private")
+
+	SetSecretPolicy(SecretPolicy{AllowedEnv: []string{"ONEOFF_SECRET_*"}, Dir: dir})
+	t.Cleanup(func() { SetSecretPolicy(SecretPolicy{}) })
+
+	tests := []struct {
+		ref  string
+		want string
+		ok   bool
+	}{
+		{"env:ONEOFF_SECRET_TOKEN", "from-env", true},
+		{"file:token", "s3cret", true},
+		{"file:" + filepath.Join(dir, "token"), "s3cret", true},
+		{"env:SERVER_ONLY", "", false},
+		{"env:ONEOFF_SECRET_MISSING", "", false},
+		{"file:../outside", "", false},
+		{"file:" + outside, "", false},
+		{"file:link", "", false},
+		{"file:missing", "", false},
+	}
+	for _, tt := range tests {
+		got, err := ResolveSecret(tt.ref)
+		if tt.ok && (err != nil || got != tt.want) {
+			t.Errorf("ResolveSecret(%q) = %q, %v; want %q", tt.ref, got, err, tt.want)
+		}
+		if !tt.ok && (err == nil || err.Error() != "secret "+tt.ref+" is not available") {
+			t.Errorf("ResolveSecret(%q) = %q, %v; want the generic error", tt.ref, got, err)
+		}
+	}
+}
+
+func TestRenderConfigMaskedChecksSecretPolicy(t *testing.T) {
+	SetSecretPolicy(SecretPolicy{AllowedEnv: []string{"ONEOFF_SECRET_*"}})
+	t.Cleanup(func() { SetSecretPolicy(SecretPolicy{}) })
+
+	data := &TemplateData{}
+	rendered, err := RenderConfig(`{"token":"{{secret \"env:ONEOFF_SECRET_TOKEN\"}}"}`, data, true)
+	if err != nil || rendered != `{"token":"`+MaskedSecret+`"}` {
+		t.Fatalf("RenderConfig = %s, %v; want the masked secret", rendered, err)
+	}
+	if _, err := RenderConfig(`{"token":"{{secret \"env:SERVER_ONLY\"}}"}`, data, true); err == nil || !strings.Contains(err.Error(), "secret env:SERVER_ONLY is not available") {
+		t.Fatalf("expected a disallowed reference to fail the masked render, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// MaskedSecret replaces secret values in rendered previews and echoed commands
+const MaskedSecret = "********"
+
+// TemplateData is what the placeholders of a job config are resolved against
+type TemplateData struct {
+	Job       TemplateJob
+	Execution TemplateExecution
+	Vars      map[string]string // Variables of the job's project
+	Params    map[string]any    // Values of the job's parameters
+}
+
+// TemplateJob exposes the job to config templates
+type TemplateJob struct {
+	ID        string
+	Name      string
+	Type      string
+	ProjectID string
+}
+
+// TemplateExecution exposes the execution to config templates
+type TemplateExecution struct {
+	ID          string
+	Attempt     int
+	ScheduledAt time.Time
+}
+
+// RenderedConfig is a job config with its placeholders resolved
+type RenderedConfig struct {
+	Template string `json:"template"`
+	Config   string `json:"config"`
+}
+
+// NewTemplateData builds the template data of a job run
+func NewTemplateData(job *Job, project *Project, rc *RunContext) *TemplateData {
+	data := &TemplateData{
+		Job: TemplateJob{
+			ID:        job.ID,
+			Name:      job.Name,
+			Type:      job.Type,
+			ProjectID: job.ProjectID,
+		},
+		Execution: TemplateExecution{
+			ID:          rc.ExecutionID,
+			Attempt:     rc.Attempt,
+			ScheduledAt: rc.ScheduledAt,
+		},
+		Vars:   map[string]string{},
+		Params: map[string]any{},
+	}
+	for name, value := range rc.Params {
+		data.Params[name] = value
+	}
+	if project != nil {
+		for key, value := range project.Env {
+			data.Vars[key] = value
+		}
+	}
+	return data
+}
+
+// ValidateConfigTemplate checks that every placeholder in the string values of a job config parses
+func ValidateConfigTemplate(config string) error {
+	if !strings.Contains(config, "{{") {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(config), &value); err != nil {
+		return fmt.Errorf("invalid config JSON: %w", err)
+	}
+
+	_, err := walkConfig(value, "config", func(path, s string) (string, error) {
+		_, err := parseConfigTemplate(path, s, nil)
+		return s, err
+	})
+	return err
+}
+
+// RenderConfig resolves the placeholders in the string values of a job config. Secrets are
+// replaced by a mask when masked is set, so that previews never reveal them; references the
+// secret policy does not allow fail either way.
+func RenderConfig(config string, data *TemplateData, masked bool) (string, error) {
+	if !strings.Contains(config, "{{") {
+		return config, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(config))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("invalid config JSON: %w", err)
+	}
+
+	secret := ResolveSecret
+	if masked {
+		secret = func(ref string) (string, error) {
+			if err := CheckSecretRef(ref); err != nil {
+				return "", err
+			}
+			return MaskedSecret, nil
+		}
+	}
+
+	rendered, err := walkConfig(value, "config", func(path, s string) (string, error) {
+		tmpl, err := parseConfigTemplate(path, s, secret)
+		if err != nil {
+			return "", err
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, data); err != nil {
+			return "", err
+		}
+		return out.String(), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(rendered); err != nil {
+		return "", fmt.Errorf("failed to encode rendered config: %w", err)
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// parseConfigTemplate parses one string value of a config. Undefined variables are errors.
+func parseConfigTemplate(path, s string, secret func(ref string) (string, error)) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"secret": func(ref string) (string, error) {
+			if secret == nil {
+				return "", nil
+			}
+			return secret(ref)
+		},
+		"shellquote": shellQuote,
+		"default": func(fallback, value any) any {
+			if value == nil || value == "" {
+				return fallback
+			}
+			return value
+		},
+	}
+	return template.New(path).Option("missingkey=error").Funcs(funcs).Parse(s)
+}
+
+// shellQuote renders a value as a single POSIX shell word, so that parameter values cannot
+// inject commands into scripts
+func shellQuote(value any) string {
+	return "'" + strings.ReplaceAll(formatParam(value), "'", `'\''`) + "'"
+}
+
+// walkConfig applies fn to every string value containing a placeholder, naming it by its path
+func walkConfig(value any, path string, fn func(path, s string) (string, error)) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			rendered, err := walkConfig(v[key], path+"."+key, fn)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = rendered
+		}
+	case []any:
+		for i, item := range v {
+			rendered, err := walkConfig(item, fmt.Sprintf("%s[%d]", path, i), fn)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = rendered
+		}
+	case string:
+		if strings.Contains(v, "{{") {
+			return fn(path, v)
+		}
+	}
+	return value, nil
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/meysam81/oneoff/internal/domain"
 	"github.com/meysam81/oneoff/internal/logging"
 	"github.com/meysam81/oneoff/internal/service"
 )
@@ -38,7 +40,8 @@ func NewHandler(
 // Response helpers
 
 type errorResponse struct {
-	Error string `json:"error"`
+	Error   string              `json:"error"`
+	Details []domain.FieldError `json:"details,omitempty"`
 }
 
 type successResponse struct {
@@ -64,6 +67,16 @@ func (h *Handler) respondError(w http.ResponseWriter, status int, message string
 	h.respondJSON(w, status, errorResponse{Error: message})
 }
 
+// respondBadRequest reports a rejected request, with field details when the error carries them
+func (h *Handler) respondBadRequest(w http.ResponseWriter, err error) {
+	var validationErr *domain.ValidationError
+	if errors.As(err, &validationErr) {
+		h.respondJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error(), Details: validationErr.Errors})
+		return
+	}
+	h.respondError(w, http.StatusBadRequest, err.Error())
+}
+
 func (h *Handler) respondSuccess(w http.ResponseWriter, status int, data interface{}) {
 	h.respondJSON(w, status, successResponse{Data: data})
 }
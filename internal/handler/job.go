@@ -2,6 +2,8 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -9,6 +11,11 @@ import (
 	"github.com/meysam81/oneoff/internal/domain"
 )
 
+// runJobRequest carries the parameter values of a run
+type runJobRequest struct {
+	Params map[string]any `json:"params"`
+}
+
 // CreateJob handles POST /api/jobs
 func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
 	var req domain.CreateJobRequest
@@ -19,13 +26,37 @@ func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
 
 	job, err := h.jobService.CreateJob(r.Context(), req)
 	if err != nil {
-		h.respondError(w, http.StatusBadRequest, err.Error())
+		h.respondBadRequest(w, err)
 		return
 	}
 
 	h.respondSuccess(w, http.StatusCreated, job)
 }
 
+// ValidateJob handles POST /api/jobs/validate
+func (h *Handler) ValidateJob(w http.ResponseWriter, r *http.Request) {
+	var req domain.ValidateJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Problems with the job itself are reported in the result; errors are either a request the
+	// service rejected outright or a server failure
+	result, err := h.jobService.ValidateJob(r.Context(), req)
+	if err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) || errors.Is(err, domain.ErrJobTypeNotFound) {
+			h.respondBadRequest(w, err)
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, result)
+}
+
 // GetJob handles GET /api/jobs/:id
 func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
@@ -106,7 +137,7 @@ func (h *Handler) UpdateJob(w http.ResponseWriter, r *http.Request) {
 			h.respondError(w, http.StatusNotFound, "Job not found")
 			return
 		}
-		h.respondError(w, http.StatusBadRequest, err.Error())
+		h.respondBadRequest(w, err)
 		return
 	}
 
@@ -145,7 +176,14 @@ func (h *Handler) ExecuteJob(w http.ResponseWriter, r *http.Request) {
 	}
 	id := parts[0]
 
-	if err := h.jobService.ExecuteJobNow(r.Context(), id); err != nil {
+	// The body is optional; it carries parameter values for the run
+	var req runJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if _, err := h.jobService.ExecuteJobNow(r.Context(), id, req.Params); err != nil {
 		if err == domain.ErrJobNotFound {
 			h.respondError(w, http.StatusNotFound, "Job not found")
 			return
@@ -157,6 +195,68 @@ func (h *Handler) ExecuteJob(w http.ResponseWriter, r *http.Request) {
 	h.respondSuccess(w, http.StatusOK, map[string]string{"message": "Job scheduled for immediate execution"})
 }
 
+// RunJob handles POST /api/jobs/:id/run
+func (h *Handler) RunJob(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[1] != "run" {
+		h.respondError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+	id := parts[0]
+
+	var req runJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	params, err := h.jobService.ExecuteJobNow(r.Context(), id, req.Params)
+	if err != nil {
+		if err == domain.ErrJobNotFound {
+			h.respondError(w, http.StatusNotFound, "Job not found")
+			return
+		}
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, map[string]any{
+		"message": "Job scheduled for immediate execution",
+		"params":  params,
+	})
+}
+
+// RenderJobConfig handles POST /api/jobs/:id/render
+func (h *Handler) RenderJobConfig(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[1] != "render" {
+		h.respondError(w, http.StatusBadRequest, "Invalid path")
+		return
+	}
+	id := parts[0]
+
+	// The body is optional; it carries sample parameter values
+	var req runJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rendered, err := h.jobService.RenderJobConfig(r.Context(), id, req.Params)
+	if err != nil {
+		if err == domain.ErrJobNotFound {
+			h.respondError(w, http.StatusNotFound, "Job not found")
+			return
+		}
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.respondSuccess(w, http.StatusOK, rendered)
+}
+
 // CloneJob handles POST /api/jobs/:id/clone
 func (h *Handler) CloneJob(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
@@ -64,17 +64,18 @@ func (h *Handler) ListExecutions(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Name        string `json:"name"`
-		Description string `json:"description"`
-		Color       string `json:"color"`
-		Icon        string `json:"icon"`
+		Name        string            `json:"name"`
+		Description string            `json:"description"`
+		Color       string            `json:"color"`
+		Icon        string            `json:"icon"`
+		Env         map[string]string `json:"env"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	project, err := h.projectService.CreateProject(r.Context(), req.Name, req.Description, req.Color, req.Icon)
+	project, err := h.projectService.CreateProject(r.Context(), req.Name, req.Description, req.Color, req.Icon, req.Env)
 	if err != nil {
 		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
@@ -117,18 +118,19 @@ func (h *Handler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	id = strings.Split(id, "/")[0]
 
 	var req struct {
-		Name        *string `json:"name"`
-		Description *string `json:"description"`
-		Color       *string `json:"color"`
-		Icon        *string `json:"icon"`
-		IsArchived  *bool   `json:"is_archived"`
+		Name        *string           `json:"name"`
+		Description *string           `json:"description"`
+		Color       *string           `json:"color"`
+		Icon        *string           `json:"icon"`
+		Env         map[string]string `json:"env"`
+		IsArchived  *bool             `json:"is_archived"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	project, err := h.projectService.UpdateProject(r.Context(), id, req.Name, req.Description, req.Color, req.Icon, req.IsArchived)
+	project, err := h.projectService.UpdateProject(r.Context(), id, req.Name, req.Description, req.Color, req.Icon, req.Env, req.IsArchived)
 	if err != nil {
 		if err == domain.ErrProjectNotFound {
 			h.respondError(w, http.StatusNotFound, "Project not found")
@@ -0,0 +1,415 @@
+package jobs
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"github.com/meysam81/x/sqlite"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	backupPrefix     = "oneoff-"
+	backupTimeLayout = "20060102T150405Z"
+)
+
+// OneOffBackupJob implements JobExecutor for consistent snapshots of the OneOff database
+type OneOffBackupJob struct {
+	config       *domain.OneOffBackupJobConfig
+	dbPath       string
+	allowedRoots []string
+	s3Policy     S3Policy
+}
+
+// backupFile is an existing backup found during rotation
+type backupFile struct {
+	name    string
+	created time.Time
+}
+
+// NewOneOffBackupJob creates a new backup job for the database at dbPath, writing inside
+// allowedRoots and uploading under the S3 policy
+func NewOneOffBackupJob(config string, dbPath string, allowedRoots []string, s3Policy S3Policy) (domain.JobExecutor, error) {
+	cfg, err := domain.ParseOneOffBackupJobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oneoff-backup job config: %w", err)
+	}
+
+	if cfg.Upload != nil && cfg.Upload.Endpoint == "" {
+		cfg.Upload.Endpoint = s3DefaultEndpoint
+	}
+
+	return &OneOffBackupJob{config: cfg, dbPath: dbPath, allowedRoots: allowedRoots, s3Policy: s3Policy}, nil
+}
+
+// Type returns the job type
+func (j *OneOffBackupJob) Type() string {
+	return "oneoff-backup"
+}
+
+// Description returns job description
+func (j *OneOffBackupJob) Description() string {
+	if j.config.Upload != nil {
+		return fmt.Sprintf("Back up the OneOff database to %s and s3://%s/%s", j.config.Directory, j.config.Upload.Bucket, j.config.Upload.Prefix)
+	}
+	return fmt.Sprintf("Back up the OneOff database to %s", j.config.Directory)
+}
+
+// Validate validates the job configuration
+func (j *OneOffBackupJob) Validate() error {
+	if j.dbPath == "" {
+		return fmt.Errorf("database path is not configured")
+	}
+	if j.config.Directory == "" {
+		return fmt.Errorf("directory is required")
+	}
+	if err := checkAllowedPath("directory", j.config.Directory, j.allowedRoots); err != nil {
+		return err
+	}
+	if j.config.KeepLast < 0 {
+		return fmt.Errorf("keep_last cannot be negative")
+	}
+	if j.config.MaxAge != "" {
+		if d, err := time.ParseDuration(j.config.MaxAge); err != nil || d <= 0 {
+			return fmt.Errorf("max_age must be a positive duration, e.g. 720h")
+		}
+	}
+
+	if u := j.config.Upload; u != nil {
+		if u.Bucket == "" {
+			return fmt.Errorf("upload.bucket is required")
+		}
+		if strings.Contains(u.Endpoint, "://") {
+			return fmt.Errorf("upload.endpoint must be host[:port] without a scheme")
+		}
+		if target := u.Endpoint + "/" + u.Bucket; !slices.Contains(j.s3Policy.BackupTargets, target) {
+			return domain.NewPolicyViolation("upload target %s is not allowed (BACKUP_UPLOAD_TARGETS)", target)
+		}
+		if err := checkS3Credentials("upload.", u.AccessKeyIDSecret, u.SecretAccessKeySecret, j.s3Policy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Execute takes a snapshot, uploads it if configured and rotates old backups
+func (j *OneOffBackupJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Set timeout if specified
+	if j.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(j.config.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	output, err := j.backup(ctx)
+
+	if ctx.Err() == context.Canceled {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 130,
+			Error:    "Job cancelled by user",
+		}, nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 124,
+			Error:    "Backup timeout",
+		}, nil
+	}
+	if err != nil {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 1,
+			Error:    fmt.Sprintf("Backup failed: %v", err),
+		}, nil
+	}
+
+	return &domain.ExecutionResult{
+		Output:   output,
+		ExitCode: 0,
+	}, nil
+}
+
+// backup runs the snapshot, upload and rotation steps in order
+func (j *OneOffBackupJob) backup(ctx context.Context) (string, error) {
+	var output strings.Builder
+
+	dir, err := resolveAllowedPath("directory", j.config.Directory, j.allowedRoots)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	name := backupPrefix + time.Now().UTC().Format(backupTimeLayout) + ".db"
+	if j.config.Compress {
+		name += ".gz"
+	}
+	path := filepath.Join(dir, name)
+
+	size, err := j.snapshot(ctx, path)
+	if err != nil {
+		return output.String(), err
+	}
+	output.WriteString(fmt.Sprintf("Snapshot written to %s (%d bytes)\n", path, size))
+
+	var client *minio.Client
+	if u := j.config.Upload; u != nil {
+		client, err = j.uploadClient()
+		if err != nil {
+			return output.String(), err
+		}
+		key := u.Prefix + name
+		if _, err := client.FPutObject(ctx, u.Bucket, key, path, minio.PutObjectOptions{ContentType: backupContentType(name)}); err != nil {
+			return output.String(), fmt.Errorf("failed to upload: %w", err)
+		}
+		output.WriteString(fmt.Sprintf("Uploaded to s3://%s/%s\n", u.Bucket, key))
+	}
+
+	if j.config.KeepLast == 0 && j.config.MaxAge == "" {
+		return output.String(), nil
+	}
+
+	removed, err := j.rotateLocal(dir, name)
+	for _, old := range removed {
+		output.WriteString(fmt.Sprintf("Removed %s\n", filepath.Join(dir, old)))
+	}
+	if err != nil {
+		return output.String(), fmt.Errorf("failed to rotate local backups: %w", err)
+	}
+
+	if client != nil {
+		removed, err := j.rotateRemote(ctx, client, name)
+		for _, old := range removed {
+			output.WriteString(fmt.Sprintf("Removed s3://%s/%s\n", j.config.Upload.Bucket, old))
+		}
+		if err != nil {
+			return output.String(), fmt.Errorf("failed to rotate uploaded backups: %w", err)
+		}
+	}
+
+	return output.String(), nil
+}
+
+// snapshot writes a consistent copy of the live database with VACUUM INTO
+func (j *OneOffBackupJob) snapshot(ctx context.Context, path string) (int64, error) {
+	db, err := sql.Open(sqlite.ENGINE, j.dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// The snapshot is built next to the target and renamed so a partial file is never mistaken for a backup
+	tmpDB := filepath.Join(filepath.Dir(path), ".snapshot-"+filepath.Base(path)+".tmp")
+	defer func() { _ = os.Remove(tmpDB) }()
+
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", tmpDB); err != nil {
+		return 0, fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	if err := os.Chmod(tmpDB, 0o600); err != nil {
+		return 0, fmt.Errorf("failed to restrict snapshot permissions: %w", err)
+	}
+
+	if err := verifySnapshot(ctx, tmpDB); err != nil {
+		return 0, err
+	}
+
+	if !j.config.Compress {
+		if err := os.Rename(tmpDB, path); err != nil {
+			return 0, fmt.Errorf("failed to move snapshot into place: %w", err)
+		}
+	} else if err := gzipFile(tmpDB, path); err != nil {
+		return 0, fmt.Errorf("failed to compress snapshot: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// verifySnapshot runs a quick integrity check on the snapshot
+func verifySnapshot(ctx context.Context, path string) error {
+	db, err := sql.Open(sqlite.ENGINE, "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	var result string
+	if err := db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&result); err != nil {
+		return fmt.Errorf("failed to verify snapshot: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("snapshot failed integrity check: %s", result)
+	}
+	return nil
+}
+
+// gzipFile compresses src into dst through a temporary file
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp) }()
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
+
+// uploadClient builds an S3 client for the upload target
+func (j *OneOffBackupJob) uploadClient() (*minio.Client, error) {
+	u := j.config.Upload
+	s3 := &S3Job{config: &domain.S3JobConfig{
+		Endpoint:              u.Endpoint,
+		Region:                u.Region,
+		Insecure:              u.Insecure,
+		PathStyle:             u.PathStyle,
+		AccessKeyIDSecret:     u.AccessKeyIDSecret,
+		SecretAccessKeySecret: u.SecretAccessKeySecret,
+		SessionTokenSecret:    u.SessionTokenSecret,
+		Bucket:                u.Bucket,
+	}, policy: j.s3Policy}
+	return s3.client()
+}
+
+// rotateLocal removes backups in dir beyond the retention policy
+func (j *OneOffBackupJob) rotateLocal(dir, current string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			if created, ok := parseBackupName(entry.Name()); ok {
+				backups = append(backups, backupFile{name: entry.Name(), created: created})
+			}
+		}
+	}
+
+	var removed []string
+	for _, old := range j.expired(backups, current) {
+		if err := os.Remove(filepath.Join(dir, old)); err != nil {
+			return removed, err
+		}
+		removed = append(removed, old)
+	}
+	return removed, nil
+}
+
+// rotateRemote removes uploaded backups beyond the retention policy
+func (j *OneOffBackupJob) rotateRemote(ctx context.Context, client *minio.Client, current string) ([]string, error) {
+	u := j.config.Upload
+
+	var backups []backupFile
+	for object := range client.ListObjects(ctx, u.Bucket, minio.ListObjectsOptions{Prefix: u.Prefix}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		name := strings.TrimPrefix(object.Key, u.Prefix)
+		if created, ok := parseBackupName(name); ok {
+			backups = append(backups, backupFile{name: name, created: created})
+		}
+	}
+
+	var removed []string
+	for _, old := range j.expired(backups, current) {
+		if err := client.RemoveObject(ctx, u.Bucket, u.Prefix+old, minio.RemoveObjectOptions{}); err != nil {
+			return removed, err
+		}
+		removed = append(removed, u.Prefix+old)
+	}
+	return removed, nil
+}
+
+// expired returns the backups to remove, never including the current one
+func (j *OneOffBackupJob) expired(backups []backupFile, current string) []string {
+	sort.Slice(backups, func(a, b int) bool {
+		return backups[a].created.After(backups[b].created)
+	})
+
+	var cutoff time.Time
+	if j.config.MaxAge != "" {
+		age, _ := time.ParseDuration(j.config.MaxAge)
+		cutoff = time.Now().Add(-age)
+	}
+
+	var names []string
+	for i, backup := range backups {
+		if backup.name == current {
+			continue
+		}
+		if (j.config.KeepLast > 0 && i >= j.config.KeepLast) || (!cutoff.IsZero() && backup.created.Before(cutoff)) {
+			names = append(names, backup.name)
+		}
+	}
+	return names
+}
+
+// parseBackupName returns the creation time encoded in a backup file name
+func parseBackupName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, backupPrefix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimPrefix(name, backupPrefix)
+	if trimmed := strings.TrimSuffix(stamp, ".db.gz"); trimmed != stamp {
+		stamp = trimmed
+	} else if trimmed := strings.TrimSuffix(stamp, ".db"); trimmed != stamp {
+		stamp = trimmed
+	} else {
+		return time.Time{}, false
+	}
+
+	created, err := time.Parse(backupTimeLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return created, true
+}
+
+// backupContentType returns the content type for an uploaded backup
+func backupContentType(name string) string {
+	if strings.HasSuffix(name, ".gz") {
+		return "application/gzip"
+	}
+	return "application/vnd.sqlite3"
+}
@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"github.com/meysam81/x/sqlite"
+)
+
+func TestOneOffBackupJobPolicy(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "oneoff.db")
+	db, err := sql.Open(sqlite.ENGINE, dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE api_keys (id TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ONEOFF_SECRET_TEST_KEY", "test")
+	setTestSecretPolicy(t)
+	policy := S3Policy{BackupTargets: []string{"s3.example.com/backups"}}
+
+	tests := []struct {
+		name    string
+		config  string
+		message string
+	}{
+		{"directory outside roots", `{"directory":"` + outside + `"}`, "directory: path is outside the allowed roots"},
+		{"upload target not listed", `{"directory":"` + root + `","upload":{"endpoint":"attacker.example.com","bucket":"loot","access_key_id_secret":"env:ONEOFF_SECRET_TEST_KEY","secret_access_key_secret":"env:ONEOFF_SECRET_TEST_KEY"}}`, "upload target attacker.example.com/loot is not allowed"},
+		{"upload target listed", `{"directory":"` + root + `","upload":{"endpoint":"s3.example.com","bucket":"backups","access_key_id_secret":"env:ONEOFF_SECRET_TEST_KEY","secret_access_key_secret":"env:ONEOFF_SECRET_TEST_KEY"}}`, ""},
+	}
+	for _, tt := range tests {
+		job, err := NewOneOffBackupJob(tt.config, dbPath, []string{root}, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = job.Validate()
+		var violation *domain.PolicyViolation
+		if tt.message == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.message != "" && (err == nil || !strings.Contains(err.Error(), tt.message) || !errors.As(err, &violation)) {
+			t.Errorf("%s: expected a policy violation containing %q, got %v", tt.name, tt.message, err)
+		}
+	}
+
+	job, err := NewOneOffBackupJob(`{"directory":"`+filepath.Join(root, "escape", "backups")+`"}`, dbPath, []string{root}, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := job.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode == 0 || !strings.Contains(result.Error, "directory resolves outside the allowed roots") {
+		t.Fatalf("expected the symlinked directory to be rejected, got exit %d, error %q", result.ExitCode, result.Error)
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) > 0 {
+		t.Fatalf("backup wrote outside the allowed roots: %v", entries)
+	}
+
+	job, err = NewOneOffBackupJob(`{"directory":"`+filepath.Join(root, "backups")+`"}`, dbPath, []string{root}, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = job.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Output, "Snapshot written to "+filepath.Join(root, "backups")) {
+		t.Fatalf("unexpected result: exit %d, output %q, error %q", result.ExitCode, result.Output, result.Error)
+	}
+}
@@ -0,0 +1,365 @@
+package jobs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// tlsVersions maps configured protocol versions to their TLS constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// CheckJob implements JobExecutor for TLS certificate and DNS checks
+type CheckJob struct {
+	config *domain.CheckJobConfig
+}
+
+// NewCheckJob creates a new check job
+func NewCheckJob(config string) (domain.JobExecutor, error) {
+	cfg, err := domain.ParseCheckJobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid check job config: %w", err)
+	}
+
+	return &CheckJob{config: cfg}, nil
+}
+
+// Type returns the job type
+func (j *CheckJob) Type() string {
+	return "check"
+}
+
+// Description returns job description
+func (j *CheckJob) Description() string {
+	var parts []string
+	if j.config.TLS != nil {
+		parts = append(parts, fmt.Sprintf("TLS %s", j.config.TLS.Address))
+	}
+	if j.config.DNS != nil {
+		parts = append(parts, fmt.Sprintf("DNS %s %s", dnsRecordType(j.config.DNS), j.config.DNS.Name))
+	}
+	return fmt.Sprintf("Check %s", strings.Join(parts, " and "))
+}
+
+// Validate validates the job configuration
+func (j *CheckJob) Validate() error {
+	if j.config.TLS == nil && j.config.DNS == nil {
+		return fmt.Errorf("at least one of tls or dns is required")
+	}
+
+	if t := j.config.TLS; t != nil {
+		if t.Address == "" {
+			return fmt.Errorf("tls.address is required")
+		}
+		if t.MinDaysValid < 0 {
+			return fmt.Errorf("tls.min_days_valid cannot be negative")
+		}
+		if _, ok := tlsVersions[t.MinVersion]; t.MinVersion != "" && !ok {
+			return fmt.Errorf("invalid tls.min_version: %s (must be 1.0, 1.1, 1.2 or 1.3)", t.MinVersion)
+		}
+		if t.CACert != "" {
+			if !x509.NewCertPool().AppendCertsFromPEM([]byte(t.CACert)) {
+				return fmt.Errorf("failed to parse tls.ca_cert")
+			}
+		}
+	}
+
+	if d := j.config.DNS; d != nil {
+		if d.Name == "" {
+			return fmt.Errorf("dns.name is required")
+		}
+		switch dnsRecordType(d) {
+		case "A", "AAAA", "CNAME", "MX", "TXT", "NS":
+		default:
+			return fmt.Errorf("invalid dns.record_type: %s (must be A, AAAA, CNAME, MX, TXT or NS)", d.RecordType)
+		}
+		if d.Match != "" && d.Match != "exact" && d.Match != "contains" {
+			return fmt.Errorf("invalid dns.match: %s (must be exact or contains)", d.Match)
+		}
+		if d.Resolver != "" {
+			if _, _, err := net.SplitHostPort(d.Resolver); err != nil {
+				return fmt.Errorf("dns.resolver must be host:port: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Execute runs the configured checks
+func (j *CheckJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Set timeout if specified
+	if j.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(j.config.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	var results []assertionResult
+	var details strings.Builder
+
+	if j.config.TLS != nil {
+		results = append(results, j.checkTLS(ctx, &details)...)
+	}
+	if j.config.DNS != nil {
+		results = append(results, j.checkDNS(ctx, &details)...)
+	}
+
+	if ctx.Err() == context.Canceled {
+		return &domain.ExecutionResult{
+			Output:   details.String(),
+			ExitCode: 130,
+			Error:    "Job cancelled by user",
+		}, nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return &domain.ExecutionResult{
+			Output:   details.String(),
+			ExitCode: 124,
+			Error:    "Check timeout",
+		}, nil
+	}
+
+	output := formatAssertionResults("Checks", results)
+	if details.Len() > 0 {
+		output += "\n" + details.String()
+	}
+
+	exitCode := 0
+	errorMsg := ""
+	if failed := failedAssertions(results); failed > 0 {
+		exitCode = 1
+		errorMsg = fmt.Sprintf("%d of %d checks failed", failed, len(results))
+	}
+
+	return &domain.ExecutionResult{
+		Output:   output,
+		ExitCode: exitCode,
+		Error:    errorMsg,
+	}, nil
+}
+
+// checkTLS connects to the endpoint and inspects the served certificate chain
+func (j *CheckJob) checkTLS(ctx context.Context, details *strings.Builder) []assertionResult {
+	cfg := j.config.TLS
+
+	address := cfg.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "443")
+	}
+	host, _, _ := net.SplitHostPort(address)
+	serverName := cfg.ServerName
+	if serverName == "" {
+		serverName = host
+	}
+
+	// Verification is done below so that an invalid chain is reported instead of aborting the handshake
+	dialer := &tls.Dialer{Config: &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true, //nolint:gosec // the chain is verified explicitly after the handshake
+		MinVersion:         tls.VersionTLS10,
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return []assertionResult{{name: fmt.Sprintf("TLS handshake with %s", address), detail: err.Error()}}
+	}
+	defer func() { _ = conn.Close() }()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return []assertionResult{{name: fmt.Sprintf("TLS handshake with %s", address), detail: "no certificate presented"}}
+	}
+	leaf := state.PeerCertificates[0]
+
+	details.WriteString(fmt.Sprintf("TLS %s:\n", address))
+	details.WriteString(fmt.Sprintf("  Protocol: %s\n", tls.VersionName(state.Version)))
+	details.WriteString(fmt.Sprintf("  Cipher: %s\n", tls.CipherSuiteName(state.CipherSuite)))
+	details.WriteString(fmt.Sprintf("  Subject: %s\n", leaf.Subject))
+	details.WriteString(fmt.Sprintf("  Issuer: %s\n", leaf.Issuer))
+	details.WriteString(fmt.Sprintf("  Not after: %s\n", leaf.NotAfter.UTC().Format(time.RFC3339)))
+	details.WriteString(fmt.Sprintf("  DNS names: %s\n", strings.Join(leaf.DNSNames, ", ")))
+
+	var results []assertionResult
+
+	opts := x509.VerifyOptions{
+		DNSName:       serverName,
+		Intermediates: x509.NewCertPool(),
+	}
+	if cfg.CACert != "" {
+		opts.Roots = x509.NewCertPool()
+		opts.Roots.AppendCertsFromPEM([]byte(cfg.CACert))
+	}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	chain := assertionResult{name: fmt.Sprintf("certificate chain valid for %s", serverName), passed: true}
+	if _, err := leaf.Verify(opts); err != nil {
+		chain.passed = false
+		chain.detail = err.Error()
+	}
+	results = append(results, chain)
+
+	daysLeft := int(time.Until(leaf.NotAfter).Hours() / 24)
+	results = append(results, assertionResult{
+		name:   fmt.Sprintf("certificate valid for at least %d days", cfg.MinDaysValid),
+		passed: time.Until(leaf.NotAfter) >= time.Duration(cfg.MinDaysValid)*24*time.Hour,
+		detail: fmt.Sprintf("%d days left", daysLeft),
+	})
+
+	for _, name := range cfg.ExpectedNames {
+		result := assertionResult{name: fmt.Sprintf("certificate covers %s", name), passed: true}
+		if err := leaf.VerifyHostname(name); err != nil {
+			result.passed = false
+		}
+		results = append(results, result)
+	}
+
+	if cfg.MinVersion != "" {
+		results = append(results, assertionResult{
+			name:   fmt.Sprintf("protocol at least TLS %s", cfg.MinVersion),
+			passed: state.Version >= tlsVersions[cfg.MinVersion],
+			detail: fmt.Sprintf("got %s", tls.VersionName(state.Version)),
+		})
+	}
+
+	return results
+}
+
+// checkDNS resolves the record and compares it to the expected values
+func (j *CheckJob) checkDNS(ctx context.Context, details *strings.Builder) []assertionResult {
+	cfg := j.config.DNS
+	recordType := dnsRecordType(cfg)
+	name := fmt.Sprintf("DNS %s %s", recordType, cfg.Name)
+
+	resolver := net.DefaultResolver
+	if cfg.Resolver != "" {
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, cfg.Resolver)
+			},
+		}
+	}
+
+	values, err := lookupDNS(ctx, resolver, recordType, cfg.Name)
+	if err != nil {
+		return []assertionResult{{name: name + " resolves", detail: err.Error()}}
+	}
+
+	details.WriteString(fmt.Sprintf("%s:\n", name))
+	for _, value := range values {
+		details.WriteString(fmt.Sprintf("  %s\n", value))
+	}
+
+	if len(cfg.Expected) == 0 {
+		return []assertionResult{{name: name + " resolves", passed: len(values) > 0}}
+	}
+
+	got := make(map[string]bool, len(values))
+	for _, value := range values {
+		got[normalizeDNSValue(value)] = true
+	}
+	expected := make(map[string]bool, len(cfg.Expected))
+	passed := true
+	for _, value := range cfg.Expected {
+		expected[normalizeDNSValue(value)] = true
+		if !got[normalizeDNSValue(value)] {
+			passed = false
+		}
+	}
+
+	match := cfg.Match
+	if match == "" {
+		match = "exact"
+	}
+	if match == "exact" && len(got) != len(expected) {
+		passed = false
+	}
+
+	return []assertionResult{{
+		name:   fmt.Sprintf("%s %s [%s]", name, match, strings.Join(cfg.Expected, ", ")),
+		passed: passed,
+		detail: fmt.Sprintf("got [%s]", strings.Join(values, ", ")),
+	}}
+}
+
+// lookupDNS returns the record values as sorted strings
+func lookupDNS(ctx context.Context, resolver *net.Resolver, recordType, name string) ([]string, error) {
+	var values []string
+
+	switch recordType {
+	case "A", "AAAA":
+		network := "ip4"
+		if recordType == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := resolver.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			values = append(values, ip.String())
+		}
+	case "CNAME":
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, cname)
+	case "MX":
+		records, err := resolver.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range records {
+			values = append(values, fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+		}
+	case "TXT":
+		records, err := resolver.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, records...)
+	case "NS":
+		records, err := resolver.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range records {
+			values = append(values, ns.Host)
+		}
+	}
+
+	sort.Strings(values)
+	return values, nil
+}
+
+// dnsRecordType returns the configured record type, defaulting to A
+func dnsRecordType(cfg *domain.DNSCheckConfig) string {
+	if cfg.RecordType == "" {
+		return "A"
+	}
+	return strings.ToUpper(cfg.RecordType)
+}
+
+// normalizeDNSValue makes host names comparable regardless of case and trailing dots
+func normalizeDNSValue(value string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), ".")
+}
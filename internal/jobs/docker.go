@@ -3,8 +3,11 @@ package jobs
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os/exec"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,6 +17,7 @@ import (
 // DockerJob implements JobExecutor for Docker containers
 type DockerJob struct {
 	config *domain.DockerJobConfig
+	run    *domain.RunContext
 }
 
 // NewDockerJob creates a new Docker job
@@ -33,14 +37,37 @@ func (j *DockerJob) Type() string {
 
 // Description returns job description
 func (j *DockerJob) Description() string {
+	if len(j.config.Steps) > 0 {
+		return fmt.Sprintf("Run %d steps in Docker container: %s", len(j.config.Steps), j.config.Image)
+	}
 	return fmt.Sprintf("Run Docker container: %s", j.config.Image)
 }
 
+// SetRunContext exposes the execution's identity to the container as ONEOFF_* variables
+func (j *DockerJob) SetRunContext(rc *domain.RunContext) {
+	j.run = rc
+}
+
 // Validate validates the job configuration
 func (j *DockerJob) Validate() error {
 	if j.config.Image == "" {
 		return fmt.Errorf("image is required")
 	}
+	if len(j.config.Steps) > 0 {
+		if len(j.config.Command) > 0 {
+			return fmt.Errorf("command and steps are mutually exclusive")
+		}
+		names := make([]string, len(j.config.Steps))
+		for i, step := range j.config.Steps {
+			if len(step.Command) == 0 {
+				return fmt.Errorf("steps[%d].command is required", i)
+			}
+			names[i] = step.Name
+		}
+		if err := validateStepNames(names); err != nil {
+			return err
+		}
+	}
 
 	// Check if Docker is available
 	cmd := exec.Command("docker", "version")
@@ -64,6 +91,10 @@ func (j *DockerJob) Execute(ctx context.Context) (*domain.ExecutionResult, error
 		defer cancel()
 	}
 
+	if len(j.config.Steps) > 0 {
+		return j.executeSteps(ctx)
+	}
+
 	// Build docker run command
 	args := []string{"run"}
 
@@ -72,7 +103,8 @@ func (j *DockerJob) Execute(ctx context.Context) (*domain.ExecutionResult, error
 		args = append(args, "--rm")
 	}
 
-	// Add environment variables
+	// Add environment variables; later flags win, so the job's own override the run context
+	args = append(args, j.runEnvArgs()...)
 	for key, value := range j.config.Env {
 		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
 	}
@@ -82,12 +114,21 @@ func (j *DockerJob) Execute(ctx context.Context) (*domain.ExecutionResult, error
 		args = append(args, "-v", fmt.Sprintf("%s:%s", host, container))
 	}
 
+	// Mount the outputs directory
+	outputs, err := newContainerOutputsDir()
+	if err != nil {
+		return nil, err
+	}
+	defer outputs.close()
+	args = append(args, outputsArgs(outputs)...)
+
 	// Add working directory
 	if j.config.WorkDir != "" {
 		args = append(args, "-w", j.config.WorkDir)
 	}
 
 	// Add image
+	imageIndex := len(args)
 	args = append(args, j.config.Image)
 
 	// Add command
@@ -95,8 +136,14 @@ func (j *DockerJob) Execute(ctx context.Context) (*domain.ExecutionResult, error
 		args = append(args, j.config.Command...)
 	}
 
+	// Sample the container's resource usage while it runs
+	stats, err := newDockerStats()
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare container stats: %w", err)
+	}
+
 	// Create command
-	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd := exec.CommandContext(ctx, "docker", append(append([]string{"run"}, stats.args()...), args[1:]...)...)
 
 	// Capture output
 	var stdout, stderr bytes.Buffer
@@ -104,7 +151,9 @@ func (j *DockerJob) Execute(ctx context.Context) (*domain.ExecutionResult, error
 	cmd.Stderr = &stderr
 
 	// Execute command
-	err := cmd.Run()
+	stats.start(ctx)
+	err = cmd.Run()
+	usage := stats.stop()
 
 	exitCode := 0
 	errorMsg := ""
@@ -135,12 +184,151 @@ func (j *DockerJob) Execute(ctx context.Context) (*domain.ExecutionResult, error
 	}
 
 	// Add command info to output
-	cmdInfo := fmt.Sprintf("Command: docker %s\n\n", strings.Join(args, " "))
+	cmdInfo := fmt.Sprintf("Command: docker %s\n\n", strings.Join(append(maskEnvArgs(args[:imageIndex]), args[imageIndex:]...), " "))
 	output = cmdInfo + output
 
-	return &domain.ExecutionResult{
+	result := &domain.ExecutionResult{
 		Output:   output,
 		ExitCode: exitCode,
 		Error:    errorMsg,
-	}, nil
+		Usage:    usage,
+		Outputs:  map[string]string{},
+	}
+	parseSetOutputs(stdout.String(), result.Outputs)
+	outputs.collect(result)
+	return result, nil
+}
+
+// DryRun pulls the image without running a container
+func (j *DockerJob) DryRun(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	out, err := exec.CommandContext(ctx, "docker", "pull", j.config.Image).CombinedOutput()
+	output := fmt.Sprintf("Command: docker pull %s\n\n%s", j.config.Image, out)
+	if err != nil {
+		exitCode := 1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: exitCode,
+			Error:    fmt.Sprintf("Failed to pull image: %v", err),
+		}, nil
+	}
+
+	return &domain.ExecutionResult{Output: output}, nil
+}
+
+// runEnvArgs returns the -e flags passing the run context to the container
+func (j *DockerJob) runEnvArgs() []string {
+	if j.run == nil {
+		return nil
+	}
+	env := j.run.Env()
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		args = append(args, "-e", key+"="+env[key])
+	}
+	return args
+}
+
+// maskEnvArgs returns a copy of docker flags with the values of -e flags masked, for echoing the command
+func maskEnvArgs(args []string) []string {
+	masked := make([]string, len(args))
+	copy(masked, args)
+	for i := 1; i < len(masked); i++ {
+		if masked[i-1] != "-e" {
+			continue
+		}
+		if key, _, ok := strings.Cut(masked[i], "="); ok {
+			masked[i] = key + "=" + domain.MaskedSecret
+		}
+	}
+	return masked
+}
+
+// outputsArgs returns the flags mounting the outputs directory and pointing the container at the outputs file
+func outputsArgs(outputs *outputsDir) []string {
+	return []string{
+		"-v", fmt.Sprintf("%s:%s", outputs.path, containerOutputsDir),
+		"-e", fmt.Sprintf("%s=%s/%s", outputsEnvVar, containerOutputsDir, outputsFileName),
+	}
+}
+
+// executeSteps runs each step in a fresh container sharing a temporary volume as working directory
+func (j *DockerJob) executeSteps(ctx context.Context) (*domain.ExecutionResult, error) {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate volume name: %w", err)
+	}
+	volume := "oneoff-steps-" + hex.EncodeToString(suffix)
+
+	if out, err := exec.CommandContext(ctx, "docker", "volume", "create", volume).CombinedOutput(); err != nil {
+		return &domain.ExecutionResult{
+			Output:   string(out),
+			ExitCode: 1,
+			Error:    fmt.Sprintf("Failed to create shared volume: %v", err),
+		}, nil
+	}
+	defer func() { _ = exec.Command("docker", "volume", "rm", "-f", volume).Run() }()
+
+	workDir := j.config.WorkDir
+	if workDir == "" {
+		workDir = "/workspace"
+	}
+
+	args := append([]string{"run", "--rm"}, j.runEnvArgs()...)
+	for key, value := range j.config.Env {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+	}
+	for host, container := range j.config.Volumes {
+		args = append(args, "-v", fmt.Sprintf("%s:%s", host, container))
+	}
+
+	outputs, err := newContainerOutputsDir()
+	if err != nil {
+		return nil, err
+	}
+	defer outputs.close()
+	args = append(args, outputsArgs(outputs)...)
+	args = append(args, "-v", fmt.Sprintf("%s:%s", volume, workDir), "-w", workDir, j.config.Image)
+
+	steps := make([]jobStep, len(j.config.Steps))
+	for i, step := range j.config.Steps {
+		stepArgs := append(append([]string{}, args...), step.Command...)
+		steps[i] = jobStep{
+			name:            step.Name,
+			continueOnError: step.ContinueOnError,
+			timeout:         step.Timeout,
+			run: func(ctx context.Context) (string, string, *domain.ResourceUsage, error) {
+				stats, err := newDockerStats()
+				if err != nil {
+					return "", "", nil, fmt.Errorf("failed to prepare container stats: %w", err)
+				}
+				cmd := exec.CommandContext(ctx, "docker", append(append([]string{"run"}, stats.args()...), stepArgs[1:]...)...)
+
+				var stdout, stderr bytes.Buffer
+				cmd.Stdout = &stdout
+				cmd.Stderr = &stderr
+
+				stats.start(ctx)
+				err = cmd.Run()
+				return stdout.String(), stderr.String(), stats.stop(), err
+			},
+		}
+	}
+
+	result := runSteps(ctx, steps, "Container execution timeout")
+	result.Output = fmt.Sprintf("Image: %s\nShared volume: %s mounted at %s\n\n", j.config.Image, volume, workDir) + result.Output
+	outputs.collect(result)
+	return result, nil
 }
@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// dockerStatsInterval is how often a running container's stats are sampled
+const dockerStatsInterval = time.Second
+
+// dockerSizeUnits maps the size suffixes printed by docker stats to bytes
+var dockerSizeUnits = map[string]float64{
+	"B":   1,
+	"kB":  1e3,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+}
+
+// dockerStats samples the memory and block I/O of a running container with docker stats.
+// Docker does not report cumulative CPU time through the CLI, so only peak memory and
+// block I/O are recorded.
+type dockerStats struct {
+	dir     string
+	mu      sync.Mutex
+	usage   *domain.ResourceUsage
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// newDockerStats prepares a sampler; the container ID is read from the cidfile docker writes
+func newDockerStats() (*dockerStats, error) {
+	dir, err := os.MkdirTemp("", "oneoff-docker-")
+	if err != nil {
+		return nil, err
+	}
+	return &dockerStats{dir: dir, stopped: make(chan struct{})}, nil
+}
+
+// args returns the docker run arguments that make the container's ID available to the sampler
+func (s *dockerStats) args() []string {
+	return []string{"--cidfile", filepath.Join(s.dir, "cid")}
+}
+
+// start samples the container in the background until stop is called
+func (s *dockerStats) start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(s.stopped)
+		ticker := time.NewTicker(dockerStatsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.sample(ctx)
+			}
+		}
+	}()
+}
+
+// stop ends sampling and returns the recorded usage, or nil if the container was never sampled
+func (s *dockerStats) stop() *domain.ResourceUsage {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.stopped
+	}
+	_ = os.RemoveAll(s.dir)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usage
+}
+
+// sample records the container's current memory and block I/O
+func (s *dockerStats) sample(ctx context.Context) {
+	cid, err := os.ReadFile(filepath.Join(s.dir, "cid"))
+	if err != nil || len(cid) == 0 {
+		return
+	}
+
+	out, err := exec.CommandContext(ctx, "docker", "stats", "--no-stream", "--format", "{{.MemUsage}}|{{.BlockIO}}", strings.TrimSpace(string(cid))).Output()
+	if err != nil {
+		return
+	}
+	mem, blockIO, ok := strings.Cut(strings.TrimSpace(string(out)), "|")
+	if !ok {
+		return
+	}
+	memUsed, _, _ := strings.Cut(mem, "/")
+	read, written, _ := strings.Cut(blockIO, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.usage == nil {
+		s.usage = &domain.ResourceUsage{}
+	}
+	if n, ok := parseDockerSize(memUsed); ok {
+		s.usage.MaxRSSKB = max(s.usage.MaxRSSKB, n/1024)
+	}
+	// Block I/O counters are cumulative, so the latest sample is the total
+	if n, ok := parseDockerSize(read); ok {
+		s.usage.ReadBytes = n
+	}
+	if n, ok := parseDockerSize(written); ok {
+		s.usage.WriteBytes = n
+	}
+}
+
+// parseDockerSize parses a human-readable size such as "12.5MiB" or "1.2MB" into bytes
+func parseDockerSize(value string) (int64, bool) {
+	value = strings.TrimSpace(value)
+	i := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i <= 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return 0, false
+	}
+	unit, ok := dockerSizeUnits[strings.TrimSpace(value[i:])]
+	if !ok {
+		return 0, false
+	}
+	return int64(n * unit), true
+}
@@ -0,0 +1,18 @@
+package jobs
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMaskEnvArgs(t *testing.T) {
+	args := []string{"run", "--rm", "-e", "API_TOKEN=hunter2", "-e", "EMPTY=", "-v", "/data:/data", "-w", "/app"}
+	want := []string{"run", "--rm", "-e", "API_TOKEN=********", "-e", "EMPTY=********", "-v", "/data:/data", "-w", "/app"}
+
+	if got := maskEnvArgs(args); !slices.Equal(got, want) {
+		t.Fatalf("maskEnvArgs = %v, want %v", got, want)
+	}
+	if args[3] != "API_TOKEN=hunter2" {
+		t.Fatal("maskEnvArgs modified its input")
+	}
+}
@@ -0,0 +1,553 @@
+package jobs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/md5"  //nolint:gosec // offered for compatibility with existing checksum files
+	"crypto/sha1" //nolint:gosec // offered for compatibility with existing checksum files
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// filesMaxListed caps the number of files listed individually in the output
+const filesMaxListed = 1000
+
+// FilesJob implements JobExecutor for declarative file operations restricted to allowed roots
+type FilesJob struct {
+	config       *domain.FilesJobConfig
+	allowedRoots []string
+}
+
+// fileEntry is a file selected by a files job
+type fileEntry struct {
+	path    string
+	rel     string
+	size    int64
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+// NewFilesJob creates a new files job limited to the allowed roots
+func NewFilesJob(config string, allowedRoots []string) (domain.JobExecutor, error) {
+	cfg, err := domain.ParseFilesJobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid files job config: %w", err)
+	}
+
+	if cfg.Format == "" {
+		cfg.Format = "tar.gz"
+	}
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = "sha256"
+	}
+
+	return &FilesJob{config: cfg, allowedRoots: allowedRoots}, nil
+}
+
+// Type returns the job type
+func (j *FilesJob) Type() string {
+	return "files"
+}
+
+// Description returns job description
+func (j *FilesJob) Description() string {
+	switch j.config.Operation {
+	case "copy", "move":
+		return fmt.Sprintf("%s %s to %s", strings.ToUpper(j.config.Operation[:1])+j.config.Operation[1:], j.config.Source, j.config.Destination)
+	case "delete":
+		return fmt.Sprintf("Delete files in %s", j.config.Source)
+	case "archive":
+		return fmt.Sprintf("Archive %s to %s", j.config.Source, j.config.Destination)
+	case "checksum":
+		return fmt.Sprintf("Compute %s checksums of %s", j.config.Algorithm, j.config.Source)
+	}
+	return "File operation"
+}
+
+// Validate validates the job configuration
+func (j *FilesJob) Validate() error {
+	if len(j.allowedRoots) == 0 {
+		return domain.NewPolicyViolation("files jobs are disabled: no allowed roots configured (FILES_ALLOWED_ROOTS)")
+	}
+
+	switch j.config.Operation {
+	case "copy", "move", "archive":
+		if j.config.Destination == "" {
+			return fmt.Errorf("destination is required for %s", j.config.Operation)
+		}
+	case "delete", "checksum":
+	default:
+		return fmt.Errorf("invalid operation: %s (must be copy, move, delete, archive or checksum)", j.config.Operation)
+	}
+
+	if j.config.Source == "" {
+		return fmt.Errorf("source is required")
+	}
+	if err := checkAllowedPath("source", j.config.Source, j.allowedRoots); err != nil {
+		return err
+	}
+	if j.config.Destination != "" {
+		if err := checkAllowedPath("destination", j.config.Destination, j.allowedRoots); err != nil {
+			return err
+		}
+	}
+
+	if j.config.Pattern != "" {
+		if _, err := filepath.Match(j.config.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if j.config.OlderThan != "" {
+		if d, err := time.ParseDuration(j.config.OlderThan); err != nil || d < 0 {
+			return fmt.Errorf("older_than must be a positive duration, e.g. 168h")
+		}
+	}
+	if j.config.Format != "tar.gz" && j.config.Format != "zip" {
+		return fmt.Errorf("invalid format: %s (must be tar.gz or zip)", j.config.Format)
+	}
+	if newFilesHash(j.config.Algorithm) == nil {
+		return fmt.Errorf("invalid algorithm: %s (must be sha256, sha1 or md5)", j.config.Algorithm)
+	}
+
+	return nil
+}
+
+// Execute performs the file operation
+func (j *FilesJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Set timeout if specified
+	if j.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(j.config.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	// Symlinks are resolved before the allowlist check so they cannot escape the roots
+	roots := resolvedRoots(j.allowedRoots)
+	source, err := filepath.EvalSymlinks(j.config.Source)
+	if err != nil {
+		return filesFailure(ctx, "", fmt.Sprintf("Source not accessible: %v", err)), nil
+	}
+	if !withinRoots(source, roots) {
+		return nil, fmt.Errorf("source resolves outside the allowed roots: %s", j.config.Source)
+	}
+
+	destination := ""
+	if j.config.Destination != "" {
+		destination, err = resolveDestination(j.config.Destination)
+		if err != nil {
+			return filesFailure(ctx, "", fmt.Sprintf("Destination not accessible: %v", err)), nil
+		}
+		if !withinRoots(destination, roots) {
+			return nil, fmt.Errorf("destination resolves outside the allowed roots: %s", j.config.Destination)
+		}
+	}
+
+	entries, err := j.collect(ctx, source, destination)
+	if err != nil {
+		return filesFailure(ctx, "", fmt.Sprintf("Failed to list files: %v", err)), nil
+	}
+
+	var log strings.Builder
+	var failures []string
+
+	if j.config.DryRun {
+		log.WriteString("Dry run, no changes made\n")
+	}
+
+	switch j.config.Operation {
+	case "copy", "move":
+		failures = j.transfer(ctx, entries, destination, roots, &log)
+	case "delete":
+		failures = j.delete(ctx, entries, &log)
+	case "archive":
+		failures = j.archive(ctx, entries, destination, &log)
+	case "checksum":
+		failures = j.checksum(ctx, entries, &log)
+	}
+
+	var totalBytes int64
+	for _, entry := range entries {
+		totalBytes += entry.size
+	}
+	log.WriteString(fmt.Sprintf("\nSummary: %d files (%d bytes) matched for %s", len(entries), totalBytes, j.config.Operation))
+	if len(failures) > 0 {
+		log.WriteString(fmt.Sprintf(", %d errors", len(failures)))
+	}
+	log.WriteString("\n")
+
+	if ctx.Err() != nil {
+		return filesFailure(ctx, log.String(), ""), nil
+	}
+	if len(failures) > 0 {
+		return &domain.ExecutionResult{
+			Output:   log.String(),
+			ExitCode: 1,
+			Error:    fmt.Sprintf("%s failed with %d errors: %s", j.config.Operation, len(failures), failures[0]),
+		}, nil
+	}
+
+	return &domain.ExecutionResult{
+		Output:   log.String(),
+		ExitCode: 0,
+	}, nil
+}
+
+// collect selects the regular files under source that match the filters
+func (j *FilesJob) collect(ctx context.Context, source, skip string) ([]fileEntry, error) {
+	var cutoff time.Time
+	if j.config.OlderThan != "" {
+		age, _ := time.ParseDuration(j.config.OlderThan)
+		cutoff = time.Now().Add(-age)
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+
+	base := source
+	if !info.IsDir() {
+		base = filepath.Dir(source)
+	}
+
+	var entries []fileEntry
+	err = filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Symlinks are never followed or touched
+		if d.IsDir() || !d.Type().IsRegular() || path == skip {
+			return nil
+		}
+		if j.config.Pattern != "" {
+			if ok, _ := filepath.Match(j.config.Pattern, d.Name()); !ok {
+				return nil
+			}
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !cutoff.IsZero() && !info.ModTime().Before(cutoff) {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(base, path)
+		entries = append(entries, fileEntry{
+			path:    path,
+			rel:     rel,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+			mode:    info.Mode(),
+		})
+		return nil
+	})
+
+	return entries, err
+}
+
+// transfer copies or moves the files into the destination directory, keeping their relative paths.
+// Each target is re-resolved before writing, as the tree may have changed since the destination was checked.
+func (j *FilesJob) transfer(ctx context.Context, entries []fileEntry, destination string, roots []string, log *strings.Builder) []string {
+	var failures []string
+	move := j.config.Operation == "move"
+
+	for i, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		target := filepath.Join(destination, entry.rel)
+		logFileLine(log, i, fmt.Sprintf("%s -> %s (%d bytes)", entry.path, target, entry.size))
+		if j.config.DryRun {
+			continue
+		}
+
+		err := func() error {
+			parent, err := resolveDestination(filepath.Dir(target))
+			if err != nil {
+				return err
+			}
+			if !withinRoots(parent, roots) {
+				return fmt.Errorf("%s resolves outside the allowed roots", target)
+			}
+			target := filepath.Join(parent, filepath.Base(target))
+			if info, err := os.Lstat(target); err == nil {
+				if info.Mode()&os.ModeSymlink != 0 {
+					return fmt.Errorf("%s is a symlink", target)
+				}
+				if !j.config.Overwrite {
+					return fmt.Errorf("%s already exists", target)
+				}
+			}
+			if err := os.MkdirAll(parent, 0o755); err != nil {
+				return err
+			}
+			if move {
+				if err := os.Rename(entry.path, target); err == nil {
+					return nil
+				}
+				// Rename fails across filesystems; fall back to copy and delete
+			}
+			if err := copyFile(entry, target); err != nil {
+				return err
+			}
+			if move {
+				return os.Remove(entry.path)
+			}
+			return nil
+		}()
+		if err != nil {
+			failures = append(failures, err.Error())
+			log.WriteString(fmt.Sprintf("  error: %v\n", err))
+		}
+	}
+
+	return failures
+}
+
+// delete removes the selected files
+func (j *FilesJob) delete(ctx context.Context, entries []fileEntry, log *strings.Builder) []string {
+	var failures []string
+
+	for i, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		logFileLine(log, i, fmt.Sprintf("delete %s (%d bytes)", entry.path, entry.size))
+		if j.config.DryRun {
+			continue
+		}
+		if err := os.Remove(entry.path); err != nil {
+			failures = append(failures, err.Error())
+			log.WriteString(fmt.Sprintf("  error: %v\n", err))
+		}
+	}
+
+	return failures
+}
+
+// archive writes the selected files into a tar.gz or zip archive
+func (j *FilesJob) archive(ctx context.Context, entries []fileEntry, destination string, log *strings.Builder) []string {
+	for i, entry := range entries {
+		logFileLine(log, i, fmt.Sprintf("add %s (%d bytes)", entry.rel, entry.size))
+	}
+	if j.config.DryRun {
+		return nil
+	}
+
+	if _, err := os.Lstat(destination); err == nil && !j.config.Overwrite {
+		return []string{fmt.Sprintf("%s already exists", destination)}
+	}
+	if err := os.MkdirAll(filepath.Dir(destination), 0o755); err != nil {
+		return []string{err.Error()}
+	}
+
+	// Write to a temporary file first so that a failed run never leaves a truncated archive
+	tmp, err := os.CreateTemp(filepath.Dir(destination), ".oneoff-archive-*")
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if j.config.Format == "zip" {
+		err = writeZip(ctx, tmp, entries)
+	} else {
+		err = writeTarGz(ctx, tmp, entries)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), destination)
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("failed to write archive: %v", err)}
+	}
+
+	if info, err := os.Stat(destination); err == nil {
+		log.WriteString(fmt.Sprintf("Wrote %s (%d bytes)\n", destination, info.Size()))
+	}
+	return nil
+}
+
+// checksum prints a checksum line per file in the sha256sum format
+func (j *FilesJob) checksum(ctx context.Context, entries []fileEntry, log *strings.Builder) []string {
+	var failures []string
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		sum, err := fileChecksum(entry.path, newFilesHash(j.config.Algorithm))
+		if err != nil {
+			failures = append(failures, err.Error())
+			log.WriteString(fmt.Sprintf("error: %v\n", err))
+			continue
+		}
+		log.WriteString(fmt.Sprintf("%s  %s\n", sum, entry.path))
+	}
+
+	return failures
+}
+
+// copyFile copies a file, preserving its mode and modification time
+func copyFile(entry fileEntry, target string) error {
+	src, err := os.Open(entry.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := createTargetFile(target, entry.mode.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(target, entry.modTime, entry.modTime)
+}
+
+// writeTarGz writes the files as a gzip-compressed tar archive
+func writeTarGz(ctx context.Context, w io.Writer, entries []fileEntry) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		info, err := os.Stat(entry.path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(entry.rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := appendFile(tw, entry.path); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeZip writes the files as a deflate-compressed zip archive
+func writeZip(ctx context.Context, w io.Writer, entries []fileEntry) error {
+	zw := zip.NewWriter(w)
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		info, err := os.Stat(entry.path)
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(entry.rel)
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if err := appendFile(fw, entry.path); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// appendFile copies the content of a file into w
+func appendFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// fileChecksum hashes a file
+func fileChecksum(path string, h hash.Hash) (string, error) {
+	if err := appendFile(h, path); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// newFilesHash returns a hash for the algorithm, or nil if it is unsupported
+func newFilesHash(algorithm string) hash.Hash {
+	switch algorithm {
+	case "sha256":
+		return sha256.New()
+	case "sha1":
+		return sha1.New() //nolint:gosec // see import
+	case "md5":
+		return md5.New() //nolint:gosec // see import
+	}
+	return nil
+}
+
+// logFileLine lists a file in the output, up to filesMaxListed entries
+func logFileLine(log *strings.Builder, index int, line string) {
+	if index < filesMaxListed {
+		log.WriteString(line + "\n")
+	} else if index == filesMaxListed {
+		log.WriteString("... (further files not listed)\n")
+	}
+}
+
+// filesFailure builds a failed result, accounting for cancellation and timeouts
+func filesFailure(ctx context.Context, output, errorMsg string) *domain.ExecutionResult {
+	exitCode := 1
+	if ctx.Err() == context.Canceled {
+		exitCode = 130
+		errorMsg = "Job cancelled by user"
+	} else if ctx.Err() == context.DeadlineExceeded {
+		exitCode = 124
+		errorMsg = "File operation timeout"
+	}
+
+	return &domain.ExecutionResult{
+		Output:   output,
+		ExitCode: exitCode,
+		Error:    errorMsg,
+	}
+}
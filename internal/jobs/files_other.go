@@ -0,0 +1,16 @@
+//go:build !unix
+
+package jobs
+
+import (
+	"fmt"
+	"os"
+)
+
+// createTargetFile creates or truncates a copy target, refusing symlinks
+func createTargetFile(path string, perm os.FileMode) (*os.File, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return nil, fmt.Errorf("%s is a symlink", path)
+	}
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+}
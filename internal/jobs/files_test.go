@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilesTransferTargets(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	victim := filepath.Join(outside, "victim")
+	if err := os.WriteFile(victim, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	source := filepath.Join(root, "src")
+	if err := os.MkdirAll(filepath.Join(source, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "sub/b.txt"} {
+		if err := os.WriteFile(filepath.Join(source, name), []byte("new"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Targets planted inside the destination that point out of the allowed roots
+	for _, dir := range []string{"linked-file", "linked-dir"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(victim, filepath.Join(root, "linked-file", "a.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "linked-dir", "sub")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		destination string
+		errContains string
+	}{
+		{"plain destination", "plain", ""},
+		{"symlinked target file", "linked-file", "is a symlink"},
+		{"symlinked target directory", "linked-dir", "resolves outside the allowed roots"},
+	}
+	for _, tt := range tests {
+		config := `{"operation":"copy","source":"` + source + `","destination":"` + filepath.Join(root, tt.destination) + `","overwrite":true}`
+		job, err := NewFilesJob(config, []string{root})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		result, err := job.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tt.errContains == "" {
+			if result.ExitCode != 0 {
+				t.Errorf("%s: exit code %d, error %q", tt.name, result.ExitCode, result.Error)
+			}
+			continue
+		}
+		if result.ExitCode == 0 || !strings.Contains(result.Output, tt.errContains) {
+			t.Errorf("%s: exit code %d, output %q; want an error containing %q", tt.name, result.ExitCode, result.Output, tt.errContains)
+		}
+	}
+
+	if data, _ := os.ReadFile(victim); string(data) != "old" {
+		t.Errorf("file outside the roots was overwritten: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "b.txt")); err == nil {
+		t.Error("file was written outside the roots")
+	}
+}
@@ -0,0 +1,363 @@
+package jobs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCJob implements JobExecutor for gRPC unary calls
+type GRPCJob struct {
+	config       *domain.GRPCJobConfig
+	allowedRoots []string
+}
+
+// NewGRPCJob creates a new gRPC job whose descriptor set file must lie in the allowed roots
+func NewGRPCJob(config string, allowedRoots []string) (domain.JobExecutor, error) {
+	cfg, err := domain.ParseGRPCJobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gRPC job config: %w", err)
+	}
+
+	return &GRPCJob{config: cfg, allowedRoots: allowedRoots}, nil
+}
+
+// Type returns the job type
+func (j *GRPCJob) Type() string {
+	return "grpc"
+}
+
+// Description returns job description
+func (j *GRPCJob) Description() string {
+	return fmt.Sprintf("gRPC call %s on %s", j.config.Method, j.config.Address)
+}
+
+// Validate validates the job configuration
+func (j *GRPCJob) Validate() error {
+	if j.config.Address == "" {
+		return fmt.Errorf("address is required")
+	}
+	if _, _, err := splitGRPCMethod(j.config.Method); err != nil {
+		return err
+	}
+	if j.config.DescriptorSet != "" && j.config.DescriptorSetFile != "" {
+		return fmt.Errorf("descriptor_set and descriptor_set_file are mutually exclusive")
+	}
+	if j.config.DescriptorSetFile != "" {
+		if err := checkAllowedPath("descriptor_set_file", j.config.DescriptorSetFile, j.allowedRoots); err != nil {
+			return err
+		}
+	}
+	if !j.config.TLS && (j.config.CACert != "" || j.config.ClientCert != "" || j.config.ClientKeySecret != "") {
+		return fmt.Errorf("certificates require tls to be enabled")
+	}
+	return nil
+}
+
+// Execute performs the gRPC call
+func (j *GRPCJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	// The timeout doubles as the call deadline
+	if j.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(j.config.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	creds := insecure.NewCredentials()
+	if j.config.TLS {
+		clientKey := ""
+		if j.config.ClientKeySecret != "" {
+			key, err := domain.ResolveSecret(j.config.ClientKeySecret)
+			if err != nil {
+				return j.failure(ctx, fmt.Sprintf("Failed to resolve client key: %v", err)), nil
+			}
+			clientKey = key
+		}
+		tlsConfig, err := buildTLSConfig(j.config.CACert, j.config.ClientCert, clientKey, j.config.ServerName, j.config.InsecureSkipVerify)
+		if err != nil {
+			return j.failure(ctx, err.Error()), nil
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.NewClient(j.config.Address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return j.failure(ctx, fmt.Sprintf("Failed to create gRPC client: %v", err)), nil
+	}
+	defer func() { _ = conn.Close() }()
+
+	method, err := j.resolveMethod(ctx, conn)
+	if err != nil {
+		return j.failure(ctx, fmt.Sprintf("Failed to resolve method: %v", err)), nil
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return j.failure(ctx, fmt.Sprintf("Method %s is a streaming method, only unary calls are supported", method.FullName())), nil
+	}
+
+	request := dynamicpb.NewMessage(method.Input())
+	if j.config.Request != "" {
+		if err := protojson.Unmarshal([]byte(j.config.Request), request); err != nil {
+			return j.failure(ctx, fmt.Sprintf("Invalid request message: %v", err)), nil
+		}
+	}
+	response := dynamicpb.NewMessage(method.Output())
+
+	if len(j.config.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(j.config.Metadata))
+	}
+
+	var header, trailer metadata.MD
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+	err = conn.Invoke(ctx, fullMethod, request, response, grpc.Header(&header), grpc.Trailer(&trailer))
+
+	st, _ := status.FromError(err)
+	output := fmt.Sprintf("Method: %s\nStatus: %s\n", fullMethod, st.Code())
+	output += formatGRPCMetadata("Headers", header)
+	output += formatGRPCMetadata("Trailers", trailer)
+
+	if st.Code() != codes.OK {
+		exitCode := 1
+		errorMsg := fmt.Sprintf("gRPC call failed with status %s: %s", st.Code(), st.Message())
+
+		if ctx.Err() == context.Canceled {
+			exitCode = 130
+			errorMsg = "gRPC call cancelled by user"
+		} else if st.Code() == codes.DeadlineExceeded {
+			exitCode = 124
+			errorMsg = fmt.Sprintf("gRPC call deadline exceeded: %s", st.Message())
+		}
+
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: exitCode,
+			Error:    errorMsg,
+		}, nil
+	}
+
+	body, err := protojson.MarshalOptions{Multiline: true}.Marshal(response)
+	if err != nil {
+		result := j.failure(ctx, fmt.Sprintf("Failed to encode response: %v", err))
+		result.Output = output
+		return result, nil
+	}
+	output += fmt.Sprintf("\nResponse:\n%s", body)
+
+	return &domain.ExecutionResult{
+		Output:   output,
+		ExitCode: 0,
+	}, nil
+}
+
+// failure builds a failed result, distinguishing cancellation and timeouts
+func (j *GRPCJob) failure(ctx context.Context, errorMsg string) *domain.ExecutionResult {
+	exitCode := 1
+	if ctx.Err() == context.Canceled {
+		exitCode = 130
+		errorMsg = "gRPC call cancelled by user"
+	} else if ctx.Err() == context.DeadlineExceeded {
+		exitCode = 124
+		errorMsg = "gRPC call timeout"
+	}
+
+	return &domain.ExecutionResult{
+		ExitCode: exitCode,
+		Error:    errorMsg,
+	}
+}
+
+// resolveMethod finds the method descriptor from the descriptor set or via server reflection
+func (j *GRPCJob) resolveMethod(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, err := splitGRPCMethod(j.config.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	var files *protoregistry.Files
+	switch {
+	case j.config.DescriptorSet != "":
+		raw, err := base64.StdEncoding.DecodeString(j.config.DescriptorSet)
+		if err != nil {
+			return nil, fmt.Errorf("descriptor_set is not valid base64: %w", err)
+		}
+		files, err = parseDescriptorSet(raw)
+		if err != nil {
+			return nil, err
+		}
+	case j.config.DescriptorSetFile != "":
+		path, err := resolveAllowedPath("descriptor_set_file", j.config.DescriptorSetFile, j.allowedRoots)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+		}
+		files, err = parseDescriptorSet(raw)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		files, err = reflectServiceFiles(ctx, conn, serviceName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", serviceName, err)
+	}
+	service, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", methodName, serviceName)
+	}
+
+	return method, nil
+}
+
+// splitGRPCMethod splits "package.Service/Method" (or "package.Service.Method") into its parts
+func splitGRPCMethod(fullMethod string) (string, string, error) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	idx := strings.LastIndex(name, "/")
+	if idx < 0 {
+		idx = strings.LastIndex(name, ".")
+	}
+	if idx <= 0 || idx == len(name)-1 {
+		return "", "", fmt.Errorf("method must be in the form package.Service/Method")
+	}
+	return name[:idx], name[idx+1:], nil
+}
+
+// parseDescriptorSet builds a file registry from a serialized FileDescriptorSet
+func parseDescriptorSet(raw []byte) (*protoregistry.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set: %w", err)
+	}
+	return files, nil
+}
+
+// reflectServiceFiles fetches the file descriptors defining a service, and their dependencies,
+// using the server reflection API
+func reflectServiceFiles(ctx context.Context, conn *grpc.ClientConn, serviceName string) (*protoregistry.Files, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection unavailable: %w", err)
+	}
+	defer func() { _ = stream.CloseSend() }()
+
+	protos := make(map[string]*descriptorpb.FileDescriptorProto)
+
+	fetch := func(req *reflectionpb.ServerReflectionRequest) error {
+		if err := stream.Send(req); err != nil {
+			return fmt.Errorf("server reflection request failed: %w", err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("server reflection request failed: %w", err)
+		}
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return fmt.Errorf("server reflection error: %s", errResp.GetErrorMessage())
+		}
+		for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return fmt.Errorf("invalid file descriptor from server: %w", err)
+			}
+			protos[fd.GetName()] = fd
+		}
+		return nil
+	}
+
+	err = fetch(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: serviceName},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers may omit dependencies they consider already sent, so request any that are missing
+	for {
+		missing := ""
+		for _, fd := range protos {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := protos[dep]; !ok {
+					missing = dep
+					break
+				}
+			}
+			if missing != "" {
+				break
+			}
+		}
+		if missing == "" {
+			break
+		}
+
+		err := fetch(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: missing},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := protos[missing]; !ok {
+			return nil, fmt.Errorf("server did not return dependency %s", missing)
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range protos {
+		set.File = append(set.File, fd)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptors from server: %w", err)
+	}
+	return files, nil
+}
+
+// formatGRPCMetadata renders metadata as an output section
+func formatGRPCMetadata(title string, md metadata.MD) string {
+	if len(md) == 0 {
+		return ""
+	}
+	output := fmt.Sprintf("\n%s:\n", title)
+	for key, values := range md {
+		output += fmt.Sprintf("%s: %s\n", key, strings.Join(values, ", "))
+	}
+	return output
+}
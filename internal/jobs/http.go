@@ -3,6 +3,7 @@ package jobs
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 type HTTPJob struct {
 	config *domain.HTTPJobConfig
 	client *req.Client
+	run    *domain.RunContext
 }
 
 // NewHTTPJob creates a new HTTP job
@@ -32,6 +34,10 @@ func NewHTTPJob(config string) (domain.JobExecutor, error) {
 		job.client.SetTimeout(time.Duration(cfg.Timeout) * time.Second)
 	}
 
+	if err := configureHTTPClient(job.client, cfg); err != nil {
+		return nil, fmt.Errorf("invalid HTTP job config: %w", err)
+	}
+
 	return job, nil
 }
 
@@ -45,6 +51,11 @@ func (j *HTTPJob) Description() string {
 	return fmt.Sprintf("HTTP %s request to %s", j.config.Method, j.config.URL)
 }
 
+// SetRunContext sends the execution's identity as request headers
+func (j *HTTPJob) SetRunContext(rc *domain.RunContext) {
+	j.run = rc
+}
+
 // Validate validates the job configuration
 func (j *HTTPJob) Validate() error {
 	if j.config.URL == "" {
@@ -63,6 +74,28 @@ func (j *HTTPJob) Validate() error {
 		return fmt.Errorf("invalid HTTP method: %s", j.config.Method)
 	}
 
+	if j.config.Retry != nil {
+		if err := validateHTTPRetry(j.config.Retry); err != nil {
+			return fmt.Errorf("invalid retry: %w", err)
+		}
+	}
+
+	if j.config.Poll != nil {
+		if err := validateHTTPPoll(j.config.Poll); err != nil {
+			return fmt.Errorf("invalid poll: %w", err)
+		}
+	}
+
+	if j.config.Assertions != nil {
+		if err := validateHTTPAssertions(j.config.Assertions); err != nil {
+			return fmt.Errorf("invalid assertions: %w", err)
+		}
+	}
+
+	if err := validateHTTPOutputs(j.config.Outputs); err != nil {
+		return fmt.Errorf("invalid outputs: %w", err)
+	}
+
 	return nil
 }
 
@@ -77,46 +110,60 @@ func (j *HTTPJob) Execute(ctx context.Context) (*domain.ExecutionResult, error)
 		method = "GET"
 	}
 
-	// Create request
-	request := j.client.R().SetContext(ctx)
-
-	// Add headers
-	for key, value := range j.config.Headers {
-		request.SetHeader(key, value)
+	retry := j.config.Retry
+	if retry == nil {
+		retry = defaultHTTPRetry()
 	}
-
-	// Add body if present
-	if j.config.Body != "" {
-		request.SetBodyString(j.config.Body)
-	}
-
-	// Execute request with retry
-	j.client.SetCommonRetryCount(3).
-		SetCommonRetryFixedInterval(2 * time.Second)
+	maxRetries := httpRetryCount(retry, method)
 
 	var resp *req.Response
 	var err error
+	var latency time.Duration
+	var attempts []httpAttempt
+
+	for {
+		// Create request
+		request := j.client.R().SetContext(ctx)
+
+		// Add headers, letting the configured ones override the run context
+		if j.run != nil {
+			request.SetHeaders(j.run.Headers())
+		}
+		for key, value := range j.config.Headers {
+			request.SetHeader(key, value)
+		}
 
-	switch method {
-	case "GET":
-		resp, err = request.Get(j.config.URL)
-	case "POST":
-		resp, err = request.Post(j.config.URL)
-	case "PUT":
-		resp, err = request.Put(j.config.URL)
-	case "PATCH":
-		resp, err = request.Patch(j.config.URL)
-	case "DELETE":
-		resp, err = request.Delete(j.config.URL)
-	case "HEAD":
-		resp, err = request.Head(j.config.URL)
-	case "OPTIONS":
-		resp, err = request.Options(j.config.URL)
-	default:
-		return nil, fmt.Errorf("unsupported HTTP method: %s", method)
+		// Add body if present
+		if j.config.Body != "" {
+			request.SetBodyString(j.config.Body)
+		}
+
+		startTime := time.Now()
+		resp, err = request.Send(method, j.config.URL)
+		latency = time.Since(startTime)
+
+		attempt := httpAttempt{err: err, latency: latency}
+		if err == nil {
+			attempt.statusCode = resp.StatusCode
+		}
+		attempts = append(attempts, attempt)
+
+		if ctx.Err() != nil || len(attempts) > maxRetries || !shouldRetryHTTP(retry, attempt) {
+			break
+		}
+
+		timer := time.NewTimer(httpRetryDelay(retry, len(attempts)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			break
+		}
 	}
 
-	if err != nil {
+	if err != nil || ctx.Err() != nil {
 		exitCode := 1
 		errorMsg := fmt.Sprintf("HTTP request failed: %v", err)
 
@@ -130,14 +177,32 @@ func (j *HTTPJob) Execute(ctx context.Context) (*domain.ExecutionResult, error)
 		}
 
 		return &domain.ExecutionResult{
-			Output:   "",
+			Output:   formatHTTPAttempts(attempts),
 			ExitCode: exitCode,
 			Error:    errorMsg,
 		}, nil
 	}
 
+	output := formatHTTPAttempts(attempts) + "\n"
+
+	// Follow the status URL of asynchronous APIs until the operation completes
+	pollExitCode, pollError := 0, ""
+	if j.config.Poll != nil && resp.StatusCode < 400 {
+		outcome := j.poll(ctx, resp)
+		output += outcome.log + "\n"
+		if outcome.resp == nil {
+			return &domain.ExecutionResult{
+				Output:   output,
+				ExitCode: outcome.exitCode,
+				Error:    outcome.errorMsg,
+			}, nil
+		}
+		resp, latency = outcome.resp, outcome.latency
+		pollExitCode, pollError = outcome.exitCode, outcome.errorMsg
+	}
+
 	statusCode := resp.StatusCode
-	output := fmt.Sprintf("Status: %d %s\n\nHeaders:\n", statusCode, resp.Status)
+	output += fmt.Sprintf("Status: %d %s\n\nHeaders:\n", statusCode, resp.Status)
 
 	// Add response headers
 	for key, values := range resp.Header {
@@ -153,14 +218,78 @@ func (j *HTTPJob) Execute(ctx context.Context) (*domain.ExecutionResult, error)
 	// Determine exit code based on status
 	exitCode := 0
 	errorMsg := ""
-	if statusCode >= 400 {
+	if statusCode >= 400 && (j.config.Assertions == nil || len(j.config.Assertions.StatusCodes) == 0) {
 		exitCode = 1
 		errorMsg = fmt.Sprintf("HTTP request returned error status: %d", statusCode)
 	}
 
+	// Evaluate assertions, which define success when configured
+	if j.config.Assertions != nil {
+		results := evaluateHTTPAssertions(j.config.Assertions, statusCode, resp.Header, body, latency)
+		output = formatAssertionResults("Assertions", results) + "\n" + output
+		if failed := failedAssertions(results); failed > 0 {
+			exitCode = 1
+			errorMsg = fmt.Sprintf("%d of %d assertions failed", failed, len(results))
+		}
+	}
+
+	if pollExitCode != 0 {
+		exitCode = pollExitCode
+		errorMsg = pollError
+	}
+
+	// Extract the configured outputs from the response body
+	var outputs map[string]string
+	if len(j.config.Outputs) > 0 {
+		outputs, err = extractJSONOutputs(j.config.Outputs, body)
+		if err != nil {
+			output += fmt.Sprintf("\n\nOutputs not extracted: %v\n", err)
+		}
+	}
+	if len(outputs) == 0 {
+		outputs = nil
+	}
+
 	return &domain.ExecutionResult{
 		Output:   output,
 		ExitCode: exitCode,
 		Error:    errorMsg,
+		Outputs:  outputs,
 	}, nil
 }
+
+// DryRun sends a HEAD request with the job's headers and authentication, never its body. Any
+// response passes, since it shows the target is reachable; servers that do not implement HEAD
+// answer 405 or 501 even when the configured method would succeed.
+func (j *HTTPJob) DryRun(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	request := j.client.R().SetContext(ctx)
+	if j.run != nil {
+		request.SetHeaders(j.run.Headers())
+	}
+	for key, value := range j.config.Headers {
+		request.SetHeader(key, value)
+	}
+
+	output := fmt.Sprintf("Would send %s %s\nChecked with HEAD %s\n", strings.ToUpper(j.config.Method), j.config.URL, j.config.URL)
+
+	startTime := time.Now()
+	resp, err := request.Send(http.MethodHead, j.config.URL)
+	latency := time.Since(startTime)
+	if err != nil {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 1,
+			Error:    fmt.Sprintf("HTTP request failed: %v", err),
+		}, nil
+	}
+
+	output += fmt.Sprintf("Status: %s (%dms)\n", resp.Status, latency.Milliseconds())
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		output += "The server does not answer HEAD requests; only its reachability was checked\n"
+	}
+	return &domain.ExecutionResult{Output: output}, nil
+}
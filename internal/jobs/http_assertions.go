@@ -0,0 +1,240 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// assertionResult is the outcome of a single HTTP assertion
+type assertionResult struct {
+	name   string
+	passed bool
+	detail string
+}
+
+// validateHTTPAssertions checks that all assertions are well-formed
+func validateHTTPAssertions(a *domain.HTTPAssertions) error {
+	for _, pattern := range a.StatusCodes {
+		if _, err := matchStatusPattern(pattern, 0); err != nil {
+			return err
+		}
+	}
+	if a.BodyRegex != "" {
+		if _, err := regexp.Compile(a.BodyRegex); err != nil {
+			return fmt.Errorf("invalid body_regex: %w", err)
+		}
+	}
+	for _, check := range a.JSONPath {
+		if _, err := parseJSONPath(check.Path); err != nil {
+			return err
+		}
+		if check.Equals == nil && check.Exists == nil {
+			return fmt.Errorf("json_path assertion %s needs equals or exists", check.Path)
+		}
+		if check.Equals != nil && !json.Valid(check.Equals) {
+			return fmt.Errorf("json_path assertion %s has an invalid equals value", check.Path)
+		}
+	}
+	if a.MaxLatencyMs < 0 {
+		return fmt.Errorf("max_latency_ms cannot be negative")
+	}
+	return nil
+}
+
+// evaluateHTTPAssertions runs every assertion against a response
+func evaluateHTTPAssertions(a *domain.HTTPAssertions, statusCode int, header http.Header, body string, latency time.Duration) []assertionResult {
+	var results []assertionResult
+
+	if len(a.StatusCodes) > 0 {
+		passed := false
+		for _, pattern := range a.StatusCodes {
+			if ok, _ := matchStatusPattern(pattern, statusCode); ok {
+				passed = true
+				break
+			}
+		}
+		results = append(results, assertionResult{
+			name:   fmt.Sprintf("status in [%s]", strings.Join(a.StatusCodes, ", ")),
+			passed: passed,
+			detail: fmt.Sprintf("got %d", statusCode),
+		})
+	}
+
+	if a.BodyRegex != "" {
+		re := regexp.MustCompile(a.BodyRegex)
+		results = append(results, assertionResult{
+			name:   fmt.Sprintf("body matches /%s/", a.BodyRegex),
+			passed: re.MatchString(body),
+		})
+	}
+
+	if len(a.JSONPath) > 0 {
+		var doc interface{}
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+		bodyErr := decoder.Decode(&doc)
+
+		for _, check := range a.JSONPath {
+			results = append(results, evaluateJSONPathAssertion(check, doc, bodyErr))
+		}
+	}
+
+	headerNames := make([]string, 0, len(a.Headers))
+	for name := range a.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		expected := a.Headers[name]
+		values, present := header[http.CanonicalHeaderKey(name)]
+		result := assertionResult{name: fmt.Sprintf("header %s present", name), passed: present}
+		if expected != "" {
+			result.name = fmt.Sprintf("header %s = %q", name, expected)
+			result.passed = false
+			for _, value := range values {
+				if value == expected {
+					result.passed = true
+					break
+				}
+			}
+			if present && !result.passed {
+				result.detail = fmt.Sprintf("got %q", strings.Join(values, ", "))
+			}
+		}
+		results = append(results, result)
+	}
+
+	if a.MaxLatencyMs > 0 {
+		results = append(results, assertionResult{
+			name:   fmt.Sprintf("latency <= %dms", a.MaxLatencyMs),
+			passed: latency.Milliseconds() <= a.MaxLatencyMs,
+			detail: fmt.Sprintf("got %dms", latency.Milliseconds()),
+		})
+	}
+
+	return results
+}
+
+// evaluateJSONPathAssertion evaluates a single JSONPath check against the decoded body
+func evaluateJSONPathAssertion(check domain.JSONPathAssertion, doc interface{}, bodyErr error) assertionResult {
+	result := assertionResult{name: check.Path}
+
+	if bodyErr != nil {
+		result.detail = "response body is not valid JSON"
+		return result
+	}
+
+	value, found, err := evalJSONPath(doc, check.Path)
+	if err != nil {
+		result.detail = err.Error()
+		return result
+	}
+
+	if check.Exists != nil {
+		result.name = fmt.Sprintf("%s exists=%t", check.Path, *check.Exists)
+		result.passed = found == *check.Exists
+		if check.Equals == nil || !result.passed {
+			return result
+		}
+	}
+
+	if check.Equals != nil {
+		result.name = fmt.Sprintf("%s == %s", check.Path, string(check.Equals))
+		if !found {
+			result.passed = false
+			result.detail = "path not found"
+			return result
+		}
+
+		var expected interface{}
+		decoder := json.NewDecoder(bytes.NewReader(check.Equals))
+		decoder.UseNumber()
+		_ = decoder.Decode(&expected)
+
+		result.passed = jsonValuesEqual(value, expected)
+		if !result.passed {
+			actual, _ := json.Marshal(value)
+			result.detail = fmt.Sprintf("got %s", actual)
+		}
+	}
+
+	return result
+}
+
+// jsonValuesEqual compares decoded JSON values, treating numbers by value
+func jsonValuesEqual(a, b interface{}) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		if aerr == nil && berr == nil {
+			return af == bf
+		}
+		return an == bn
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// matchStatusPattern reports whether code matches "204", "2xx" or "200-299"
+func matchStatusPattern(pattern string, code int) (bool, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+	if len(pattern) == 3 && strings.HasSuffix(pattern, "xx") && pattern[0] >= '1' && pattern[0] <= '5' {
+		return code/100 == int(pattern[0]-'0'), nil
+	}
+
+	if from, to, ok := strings.Cut(pattern, "-"); ok {
+		low, err1 := strconv.Atoi(strings.TrimSpace(from))
+		high, err2 := strconv.Atoi(strings.TrimSpace(to))
+		if err1 != nil || err2 != nil || low > high {
+			return false, fmt.Errorf("invalid status code range: %s", pattern)
+		}
+		return code >= low && code <= high, nil
+	}
+
+	exact, err := strconv.Atoi(pattern)
+	if err != nil || exact < 100 || exact > 599 {
+		return false, fmt.Errorf("invalid status code: %s", pattern)
+	}
+	return code == exact, nil
+}
+
+// formatAssertionResults renders assertion outcomes for the execution output
+func formatAssertionResults(title string, results []assertionResult) string {
+	var sb strings.Builder
+	sb.WriteString(title + ":\n")
+	for _, result := range results {
+		status := "PASS"
+		if !result.passed {
+			status = "FAIL"
+		}
+		sb.WriteString(fmt.Sprintf("  [%s] %s", status, result.name))
+		if result.detail != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", result.detail))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// failedAssertions counts the assertions that did not pass
+func failedAssertions(results []assertionResult) int {
+	failed := 0
+	for _, result := range results {
+		if !result.passed {
+			failed++
+		}
+	}
+	return failed
+}
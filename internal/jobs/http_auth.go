@@ -0,0 +1,405 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imroc/req/v3"
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// configureHTTPClient applies TLS, redirect, proxy and authentication settings to the client
+func configureHTTPClient(client *req.Client, cfg *domain.HTTPJobConfig) error {
+	if cfg.CACert != "" || cfg.ClientCert != "" || cfg.ClientKeySecret != "" || cfg.InsecureSkipVerify {
+		clientKey := ""
+		if cfg.ClientKeySecret != "" {
+			key, err := domain.ResolveSecret(cfg.ClientKeySecret)
+			if err != nil {
+				return fmt.Errorf("failed to resolve client key: %w", err)
+			}
+			clientKey = key
+		}
+
+		tlsConfig, err := buildTLSConfig(cfg.CACert, cfg.ClientCert, clientKey, "", cfg.InsecureSkipVerify)
+		if err != nil {
+			return err
+		}
+		client.SetTLSClientConfig(tlsConfig)
+	}
+
+	if cfg.MaxRedirects != nil || cfg.SameHostRedirects {
+		maxRedirects := 10
+		if cfg.MaxRedirects != nil {
+			maxRedirects = *cfg.MaxRedirects
+		}
+		switch {
+		case maxRedirects < 0:
+			return fmt.Errorf("max_redirects cannot be negative")
+		case maxRedirects == 0:
+			client.SetRedirectPolicy(req.NoRedirectPolicy())
+		case cfg.SameHostRedirects:
+			client.SetRedirectPolicy(req.MaxRedirectPolicy(maxRedirects), req.SameHostRedirectPolicy())
+		default:
+			client.SetRedirectPolicy(req.MaxRedirectPolicy(maxRedirects))
+		}
+	}
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return fmt.Errorf("invalid proxy URL: %s", cfg.Proxy)
+		}
+		client.SetProxyURL(cfg.Proxy)
+	}
+
+	if cfg.Auth != nil {
+		if err := configureHTTPAuth(client, cfg); err != nil {
+			return fmt.Errorf("invalid auth: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// configureHTTPAuth installs the configured authentication mode on the client
+func configureHTTPAuth(client *req.Client, cfg *domain.HTTPJobConfig) error {
+	auth := cfg.Auth
+
+	// Credentials are added at the transport level and only to requests for the job's own host
+	// and port, so that they never follow a redirect elsewhere
+	jobURL, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	authorize := func(sign func(r *http.Request) error) {
+		client.GetTransport().WrapRoundTripFunc(func(rt http.RoundTripper) req.HttpRoundTripFunc {
+			return func(r *http.Request) (*http.Response, error) {
+				r = r.Clone(r.Context())
+				if !strings.EqualFold(r.URL.Host, jobURL.Host) {
+					r.Header.Del("Authorization")
+					return rt.RoundTrip(r)
+				}
+				if err := sign(r); err != nil {
+					return nil, err
+				}
+				return rt.RoundTrip(r)
+			}
+		})
+	}
+
+	switch auth.Type {
+	case "basic":
+		if auth.Username == "" || auth.PasswordSecret == "" {
+			return fmt.Errorf("basic auth requires username and password_secret")
+		}
+		password, err := domain.ResolveSecret(auth.PasswordSecret)
+		if err != nil {
+			return err
+		}
+		authorize(func(r *http.Request) error {
+			r.SetBasicAuth(auth.Username, password)
+			return nil
+		})
+
+	case "bearer":
+		if auth.TokenSecret == "" {
+			return fmt.Errorf("bearer auth requires token_secret")
+		}
+		token, err := domain.ResolveSecret(auth.TokenSecret)
+		if err != nil {
+			return err
+		}
+		authorize(func(r *http.Request) error {
+			r.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		})
+
+	case "oauth2":
+		if auth.TokenURL == "" || auth.ClientID == "" || auth.ClientSecretSecret == "" {
+			return fmt.Errorf("oauth2 auth requires token_url, client_id and client_secret_secret")
+		}
+		clientSecret, err := domain.ResolveSecret(auth.ClientSecretSecret)
+		if err != nil {
+			return err
+		}
+
+		// Token requests share the job's TLS and proxy settings but not its auth
+		tokenClient := req.C().SetTimeout(30 * time.Second).SetTLSClientConfig(client.GetTLSClientConfig())
+		if cfg.Proxy != "" {
+			tokenClient.SetProxyURL(cfg.Proxy)
+		}
+
+		authorize(func(r *http.Request) error {
+			token, err := oauth2Token(r.Context(), tokenClient, auth, clientSecret)
+			if err != nil {
+				return err
+			}
+			r.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		})
+
+	case "aws_sigv4":
+		if auth.Region == "" || auth.Service == "" || auth.AccessKeyIDSecret == "" || auth.SecretAccessKeySecret == "" {
+			return fmt.Errorf("aws_sigv4 auth requires region, service, access_key_id_secret and secret_access_key_secret")
+		}
+		credentials := awsCredentials{}
+		if credentials.accessKeyID, err = domain.ResolveSecret(auth.AccessKeyIDSecret); err != nil {
+			return err
+		}
+		if credentials.secretAccessKey, err = domain.ResolveSecret(auth.SecretAccessKeySecret); err != nil {
+			return err
+		}
+		if auth.SessionTokenSecret != "" {
+			if credentials.sessionToken, err = domain.ResolveSecret(auth.SessionTokenSecret); err != nil {
+				return err
+			}
+		}
+
+		// Signing happens at the transport level so that it covers the final headers and body
+		authorize(func(r *http.Request) error {
+			return signAWSv4(r, credentials, auth.Region, auth.Service, time.Now())
+		})
+
+	default:
+		return fmt.Errorf("unsupported auth type: %s (must be basic, bearer, oauth2 or aws_sigv4)", auth.Type)
+	}
+
+	return nil
+}
+
+// oauth2CachedToken is an access token cached until shortly before it expires
+type oauth2CachedToken struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+// oauth2TokenCache is shared by all HTTP jobs so that tokens are reused across executions
+var oauth2TokenCache = struct {
+	sync.Mutex
+	tokens map[string]oauth2CachedToken
+}{tokens: make(map[string]oauth2CachedToken)}
+
+// oauth2Token returns a cached access token or fetches a new one with the client credentials grant.
+// Tokens are cached per client secret, so only jobs holding the same credentials share them.
+func oauth2Token(ctx context.Context, client *req.Client, auth *domain.HTTPAuthConfig, clientSecret string) (string, error) {
+	secretHash := sha256.Sum256([]byte(clientSecret))
+	key := strings.Join([]string{auth.TokenURL, auth.ClientID, hex.EncodeToString(secretHash[:]), strings.Join(auth.Scopes, " "), auth.Audience}, "|")
+
+	oauth2TokenCache.Lock()
+	cached, ok := oauth2TokenCache.tokens[key]
+	oauth2TokenCache.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.accessToken, nil
+	}
+
+	form := map[string]string{"grant_type": "client_credentials"}
+	if len(auth.Scopes) > 0 {
+		form["scope"] = strings.Join(auth.Scopes, " ")
+	}
+	if auth.Audience != "" {
+		form["audience"] = auth.Audience
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	resp, err := client.R().
+		SetContext(ctx).
+		SetBasicAuth(auth.ClientID, clientSecret).
+		SetFormData(form).
+		SetSuccessResult(&body).
+		Post(auth.TokenURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch OAuth2 token: %w", err)
+	}
+	if !resp.IsSuccessState() {
+		return "", fmt.Errorf("failed to fetch OAuth2 token: token endpoint returned status %d", resp.StatusCode)
+	}
+	if body.AccessToken == "" {
+		return "", fmt.Errorf("failed to fetch OAuth2 token: response has no access_token")
+	}
+
+	expiresIn := time.Duration(body.ExpiresIn) * time.Second
+	if expiresIn <= 0 {
+		expiresIn = 5 * time.Minute
+	}
+	// Refresh a little early so that tokens don't expire mid-request
+	margin := 30 * time.Second
+	if expiresIn <= 2*margin {
+		margin = expiresIn / 2
+	}
+
+	oauth2TokenCache.Lock()
+	oauth2TokenCache.tokens[key] = oauth2CachedToken{
+		accessToken: body.AccessToken,
+		expiresAt:   time.Now().Add(expiresIn - margin),
+	}
+	oauth2TokenCache.Unlock()
+
+	return body.AccessToken, nil
+}
+
+// awsCredentials holds resolved AWS credentials
+type awsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// signAWSv4 signs the request in place with AWS Signature Version 4
+func signAWSv4(r *http.Request, creds awsCredentials, region, service string, now time.Time) error {
+	var body []byte
+	if r.GetBody != nil {
+		rc, err := r.GetBody()
+		if err != nil {
+			return fmt.Errorf("failed to read request body for signing: %w", err)
+		}
+		body, err = io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read request body for signing: %w", err)
+		}
+	} else if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read request body for signing: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	r.Header.Set("X-Amz-Date", amzDate)
+	if service == "s3" {
+		r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	if creds.sessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	// Canonical headers: host, content-type and every x-amz-* header
+	headers := map[string]string{"host": host}
+	for name, values := range r.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			trimmed := make([]string, len(values))
+			for i, value := range values {
+				trimmed[i] = strings.Join(strings.Fields(value), " ")
+			}
+			headers[lower] = strings.Join(trimmed, ",")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := r.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+	if service != "s3" {
+		// Every service except S3 expects each path segment to be encoded twice
+		segments := strings.Split(canonicalURI, "/")
+		for i, segment := range segments {
+			segments[i] = awsURIEncode(segment)
+		}
+		canonicalURI = strings.Join(segments, "/")
+	}
+
+	query := r.URL.Query()
+	queryKeys := make([]string, 0, len(query))
+	for key := range query {
+		queryKeys = append(queryKeys, key)
+	}
+	sort.Strings(queryKeys)
+	var queryParts []string
+	for _, key := range queryKeys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			queryParts = append(queryParts, awsURIEncode(key)+"="+awsURIEncode(value))
+		}
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalURI,
+		strings.Join(queryParts, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	r.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.accessKeyID, scope, signedHeaders, signature))
+
+	return nil
+}
+
+// awsURIEncode percent-encodes everything except RFC 3986 unreserved characters
+func awsURIEncode(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			sb.WriteByte(c)
+		} else {
+			sb.WriteString(fmt.Sprintf("%%%02X", c))
+		}
+	}
+	return sb.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
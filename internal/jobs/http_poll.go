@@ -0,0 +1,203 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/imroc/req/v3"
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+const (
+	httpDefaultPollInterval = 5
+	httpDefaultPollDeadline = 3600
+)
+
+// httpPollOutcome is the result of polling a status URL
+type httpPollOutcome struct {
+	resp     *req.Response // last status response, nil if none was received
+	latency  time.Duration
+	log      string
+	exitCode int
+	errorMsg string
+}
+
+// validateHTTPPoll checks that the polling configuration is well-formed
+func validateHTTPPoll(p *domain.HTTPPollConfig) error {
+	if p.StatusURLHeader != "" && p.StatusURLPath != "" {
+		return fmt.Errorf("status_url_header and status_url_path are mutually exclusive")
+	}
+	if p.StatusURLPath != "" {
+		if _, err := parseJSONPath(p.StatusURLPath); err != nil {
+			return err
+		}
+	}
+	if p.Interval < 0 || p.Deadline < 0 {
+		return fmt.Errorf("interval and deadline cannot be negative")
+	}
+	if p.SuccessWhen != nil {
+		if err := validateHTTPAssertions(p.SuccessWhen); err != nil {
+			return fmt.Errorf("invalid success_when: %w", err)
+		}
+	}
+	if p.FailureWhen != nil {
+		if err := validateHTTPAssertions(p.FailureWhen); err != nil {
+			return fmt.Errorf("invalid failure_when: %w", err)
+		}
+	}
+	return nil
+}
+
+// poll follows the status URL returned by the submit response until a success or
+// failure condition matches, the deadline passes or the job is cancelled
+func (j *HTTPJob) poll(ctx context.Context, submit *req.Response) httpPollOutcome {
+	cfg := j.config.Poll
+	var log strings.Builder
+	log.WriteString("Polls:\n")
+
+	statusURL, err := j.statusURL(submit)
+	if err != nil {
+		log.WriteString(fmt.Sprintf("  %v\n", err))
+		return httpPollOutcome{log: log.String(), exitCode: 1, errorMsg: err.Error()}
+	}
+	log.WriteString(fmt.Sprintf("  status URL: %s\n", statusURL))
+
+	interval := time.Duration(cfg.Interval) * time.Second
+	if cfg.Interval == 0 {
+		interval = httpDefaultPollInterval * time.Second
+	}
+	deadline := time.Duration(cfg.Deadline) * time.Second
+	if cfg.Deadline == 0 {
+		deadline = httpDefaultPollDeadline * time.Second
+	}
+	pollCtx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+
+	outcome := httpPollOutcome{}
+	for n := 1; ; n++ {
+		// Wait before every poll, honoring cancellation
+		timer := time.NewTimer(interval)
+		select {
+		case <-pollCtx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if pollCtx.Err() != nil {
+			break
+		}
+
+		request := j.client.R().SetContext(pollCtx)
+		if j.run != nil {
+			request.SetHeaders(j.run.Headers())
+		}
+		for key, value := range j.config.Headers {
+			request.SetHeader(key, value)
+		}
+
+		startTime := time.Now()
+		resp, err := request.Get(statusURL)
+		latency := time.Since(startTime)
+
+		if err != nil {
+			if pollCtx.Err() != nil {
+				break
+			}
+			// Transient errors don't end polling, the deadline does
+			log.WriteString(fmt.Sprintf("  #%d error: %v (%dms)\n", n, err, latency.Milliseconds()))
+			continue
+		}
+
+		outcome.resp, outcome.latency = resp, latency
+		body := resp.String()
+
+		state := "pending"
+		if pollConditionMatches(cfg.FailureWhen, resp, body, latency, func() bool { return resp.StatusCode >= 400 }) {
+			state = "failure"
+		} else if pollConditionMatches(cfg.SuccessWhen, resp, body, latency, func() bool {
+			return resp.StatusCode/100 == 2 && resp.StatusCode != 202
+		}) {
+			state = "success"
+		}
+		log.WriteString(fmt.Sprintf("  #%d status %d (%dms) %s\n", n, resp.StatusCode, latency.Milliseconds(), state))
+
+		switch state {
+		case "failure":
+			outcome.exitCode = 1
+			outcome.errorMsg = fmt.Sprintf("Asynchronous operation failed after %d polls", n)
+			outcome.log = log.String()
+			return outcome
+		case "success":
+			outcome.log = log.String()
+			return outcome
+		}
+	}
+
+	outcome.log = log.String()
+	if ctx.Err() == context.Canceled {
+		outcome.exitCode = 130
+		outcome.errorMsg = "HTTP request cancelled by user"
+	} else if ctx.Err() == context.DeadlineExceeded {
+		outcome.exitCode = 124
+		outcome.errorMsg = "HTTP request timeout"
+	} else {
+		outcome.exitCode = 124
+		outcome.errorMsg = "Polling deadline exceeded"
+	}
+	return outcome
+}
+
+// statusURL extracts the absolute status URL from the submit response
+func (j *HTTPJob) statusURL(submit *req.Response) (string, error) {
+	cfg := j.config.Poll
+	raw := ""
+
+	if cfg.StatusURLPath != "" {
+		var doc interface{}
+		if err := json.Unmarshal(submit.Bytes(), &doc); err != nil {
+			return "", fmt.Errorf("response body is not valid JSON, cannot extract status URL")
+		}
+		value, found, err := evalJSONPath(doc, cfg.StatusURLPath)
+		if err != nil {
+			return "", err
+		}
+		str, ok := value.(string)
+		if !found || !ok {
+			return "", fmt.Errorf("status URL not found at %s", cfg.StatusURLPath)
+		}
+		raw = str
+	} else {
+		header := cfg.StatusURLHeader
+		if header == "" {
+			header = "Location"
+		}
+		raw = submit.Header.Get(header)
+		if raw == "" {
+			return "", fmt.Errorf("status URL header %s missing from response", header)
+		}
+	}
+
+	// Relative status URLs are resolved against the submitted URL
+	base, err := url.Parse(j.config.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid status URL %q: %w", raw, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// pollConditionMatches reports whether every assertion of the condition passes,
+// falling back to the default check when no condition is configured
+func pollConditionMatches(cond *domain.HTTPAssertions, resp *req.Response, body string, latency time.Duration, fallback func() bool) bool {
+	if cond == nil {
+		return fallback()
+	}
+	results := evaluateHTTPAssertions(cond, resp.StatusCode, resp.Header, body, latency)
+	return len(results) > 0 && failedAssertions(results) == 0
+}
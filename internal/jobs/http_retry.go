@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+const (
+	httpDefaultRetryCount    = 3
+	httpDefaultRetryInterval = 2 * time.Second
+	httpDefaultRetryMax      = 30 * time.Second
+)
+
+// httpAttempt records a single HTTP request attempt
+type httpAttempt struct {
+	statusCode int
+	err        error
+	latency    time.Duration
+}
+
+// defaultHTTPRetry is used when a job does not configure retries
+func defaultHTTPRetry() *domain.HTTPRetryConfig {
+	return &domain.HTTPRetryConfig{Count: httpDefaultRetryCount}
+}
+
+// validateHTTPRetry checks that the retry configuration is well-formed
+func validateHTTPRetry(r *domain.HTTPRetryConfig) error {
+	if r.Count < 0 {
+		return fmt.Errorf("count cannot be negative")
+	}
+	if r.Backoff != "" && r.Backoff != "fixed" && r.Backoff != "exponential" {
+		return fmt.Errorf("invalid backoff: %s (must be fixed or exponential)", r.Backoff)
+	}
+	if r.IntervalMs < 0 || r.MaxIntervalMs < 0 {
+		return fmt.Errorf("intervals cannot be negative")
+	}
+	for _, pattern := range r.StatusCodes {
+		if _, err := matchStatusPattern(pattern, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isIdempotentMethod reports whether repeating a request with this method is safe
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// httpRetryCount returns the number of retries allowed for the method
+func httpRetryCount(r *domain.HTTPRetryConfig, method string) int {
+	if !isIdempotentMethod(method) && !r.AllowNonIdempotent {
+		return 0
+	}
+	return r.Count
+}
+
+// shouldRetryHTTP reports whether an attempt failed in a way the config allows retrying
+func shouldRetryHTTP(r *domain.HTTPRetryConfig, attempt httpAttempt) bool {
+	if attempt.err != nil {
+		return r.NetworkErrors == nil || *r.NetworkErrors
+	}
+	for _, pattern := range r.StatusCodes {
+		if ok, _ := matchStatusPattern(pattern, attempt.statusCode); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// httpRetryDelay returns how long to wait before the given retry (1-based)
+func httpRetryDelay(r *domain.HTTPRetryConfig, retry int) time.Duration {
+	interval := httpDefaultRetryInterval
+	if r.IntervalMs > 0 {
+		interval = time.Duration(r.IntervalMs) * time.Millisecond
+	}
+	if r.Backoff != "exponential" {
+		return interval
+	}
+
+	maxInterval := httpDefaultRetryMax
+	if r.MaxIntervalMs > 0 {
+		maxInterval = time.Duration(r.MaxIntervalMs) * time.Millisecond
+	}
+	for i := 1; i < retry && interval < maxInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxInterval {
+		interval = maxInterval
+	}
+	return interval
+}
+
+// formatHTTPAttempts renders the attempt log for the execution output
+func formatHTTPAttempts(attempts []httpAttempt) string {
+	var sb strings.Builder
+	sb.WriteString("Attempts:\n")
+	for i, attempt := range attempts {
+		if attempt.err != nil {
+			sb.WriteString(fmt.Sprintf("  #%d error: %v (%dms)\n", i+1, attempt.err, attempt.latency.Milliseconds()))
+		} else {
+			sb.WriteString(fmt.Sprintf("  #%d status %d (%dms)\n", i+1, attempt.statusCode, attempt.latency.Milliseconds()))
+		}
+	}
+	return sb.String()
+}
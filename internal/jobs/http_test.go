@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPDryRunReportsConfiguredMethod(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	job, err := NewHTTPJob(`{"url":"` + server.URL + `/hooks","method":"post","body":"{\"deploy\":true}"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := job.(*HTTPJob).DryRun(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(methods) != 1 || methods[0] != http.MethodHead {
+		t.Fatalf("expected a single HEAD request, got %v", methods)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("a 405 on HEAD should not fail the dry run: %s", result.Error)
+	}
+	for _, want := range []string{"Would send POST " + server.URL + "/hooks", "Status: 405", "does not answer HEAD"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("output %q does not contain %q", result.Output, want)
+		}
+	}
+}
+
+func TestHTTPAuthNotSentAcrossRedirects(t *testing.T) {
+	t.Setenv("ONEOFF_SECRET_TEST_AUTH", "hunter2")
+	setTestSecretPolicy(t)
+
+	var leaked []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			leaked = append(leaked, auth)
+		}
+	}))
+	defer other.Close()
+
+	var sent []string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"issued","expires_in":3600}`))
+			return
+		}
+		sent = append(sent, r.Header.Get("Authorization"))
+		http.Redirect(w, r, other.URL+"/elsewhere", http.StatusFound)
+	}))
+	defer target.Close()
+
+	tests := []struct {
+		name string
+		auth string
+	}{
+		{"basic", `{"type":"basic","username":"ops","password_secret":"env:ONEOFF_SECRET_TEST_AUTH"}`},
+		{"bearer", `{"type":"bearer","token_secret":"env:ONEOFF_SECRET_TEST_AUTH"}`},
+		{"oauth2", `{"type":"oauth2","token_url":"` + target.URL + `/token","client_id":"oneoff","client_secret_secret":"env:ONEOFF_SECRET_TEST_AUTH"}`},
+		{"aws_sigv4", `{"type":"aws_sigv4","region":"eu-west-1","service":"execute-api","access_key_id_secret":"env:ONEOFF_SECRET_TEST_AUTH","secret_access_key_secret":"env:ONEOFF_SECRET_TEST_AUTH"}`},
+	}
+	for _, tt := range tests {
+		leaked, sent = nil, nil
+		job, err := NewHTTPJob(`{"url":"` + target.URL + `/start","auth":` + tt.auth + `}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := job.Execute(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ExitCode != 0 {
+			t.Fatalf("%s: unexpected failure: %s", tt.name, result.Error)
+		}
+		if len(sent) != 1 || sent[0] == "" {
+			t.Errorf("%s: expected credentials on the job's host, got %q", tt.name, sent)
+		}
+		if len(leaked) > 0 {
+			t.Errorf("%s: credentials followed the redirect to another host: %q", tt.name, leaked)
+		}
+	}
+}
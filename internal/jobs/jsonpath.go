@@ -0,0 +1,101 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evalJSONPath evaluates a simple JSONPath expression against a decoded JSON document.
+// Supported syntax: $ root, .field, ['field'] / ["field"] and [index] (negative indexes
+// count from the end). It returns false when the path does not exist in the document.
+func evalJSONPath(doc interface{}, path string) (interface{}, bool, error) {
+	tokens, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			if token.isIndex {
+				return nil, false, nil
+			}
+			value, ok := node[token.key]
+			if !ok {
+				return nil, false, nil
+			}
+			current = value
+		case []interface{}:
+			if !token.isIndex {
+				return nil, false, nil
+			}
+			idx := token.index
+			if idx < 0 {
+				idx += len(node)
+			}
+			if idx < 0 || idx >= len(node) {
+				return nil, false, nil
+			}
+			current = node[idx]
+		default:
+			return nil, false, nil
+		}
+	}
+
+	return current, true, nil
+}
+
+// jsonPathToken is a single step of a JSONPath expression
+type jsonPathToken struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath splits a JSONPath expression into tokens
+func parseJSONPath(path string) ([]jsonPathToken, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", path)
+	}
+
+	var tokens []jsonPathToken
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: empty field name", path)
+			}
+			tokens = append(tokens, jsonPathToken{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath %q: unclosed bracket", path)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				tokens = append(tokens, jsonPathToken{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: unsupported selector [%s]", path, inner)
+			}
+			tokens = append(tokens, jsonPathToken{index: idx, isIndex: true})
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected character %q", path, rest[0])
+		}
+	}
+
+	return tokens, nil
+}
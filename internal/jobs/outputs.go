@@ -0,0 +1,208 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+const (
+	// outputsEnvVar points jobs at the file they may write their outputs to
+	outputsEnvVar = "ONEOFF_OUTPUTS_FILE"
+	// outputsFileName is the name of the outputs file inside the outputs directory
+	outputsFileName = "outputs.json"
+	// containerOutputsDir is where the outputs directory is mounted in containers
+	containerOutputsDir = "/oneoff/outputs"
+)
+
+var (
+	// outputNamePattern restricts the names of outputs extracted from HTTP responses
+	outputNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	// setOutputPattern matches `::set-output name=value` lines
+	setOutputPattern = regexp.MustCompile(`^::set-output ([A-Za-z_][A-Za-z0-9_.-]*)=(.*)$`)
+)
+
+// maxOutputsFileSize caps the size of the outputs file read after a job
+const maxOutputsFileSize = 1 << 20
+
+// outputsDir is a temporary directory holding the outputs file of one execution
+type outputsDir struct {
+	path string // directory handed to the job
+	root string // directory removed on close
+}
+
+// newOutputsDir creates an outputs directory only the job's user can write to: the server's
+// user, or the run_as user of a sandboxed shell job
+func newOutputsDir(sb *shellSandbox) (*outputsDir, error) {
+	path, err := os.MkdirTemp("", "oneoff-outputs-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outputs directory: %w", err)
+	}
+	if sb != nil {
+		if err := sb.chown(path); err != nil {
+			_ = os.RemoveAll(path)
+			return nil, fmt.Errorf("failed to create outputs directory: %w", err)
+		}
+	}
+	return &outputsDir{path: path, root: path}, nil
+}
+
+// newContainerOutputsDir creates an outputs directory for containers, which may run as any user.
+// It can be written but not listed by everyone, and lives in a directory only the server can
+// enter, so that it is reachable only through the container's bind mount.
+func newContainerOutputsDir() (*outputsDir, error) {
+	root, err := os.MkdirTemp("", "oneoff-outputs-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outputs directory: %w", err)
+	}
+	path := filepath.Join(root, "outputs")
+	if err := os.Mkdir(path, 0o700); err != nil {
+		_ = os.RemoveAll(root)
+		return nil, fmt.Errorf("failed to create outputs directory: %w", err)
+	}
+	if err := os.Chmod(path, os.ModeSticky|0o733); err != nil {
+		_ = os.RemoveAll(root)
+		return nil, fmt.Errorf("failed to create outputs directory: %w", err)
+	}
+	return &outputsDir{path: path, root: root}, nil
+}
+
+// file returns the host path of the outputs file
+func (d *outputsDir) file() string {
+	return filepath.Join(d.path, outputsFileName)
+}
+
+// close removes the outputs directory
+func (d *outputsDir) close() {
+	_ = os.RemoveAll(d.root)
+}
+
+// collect adds the outputs written to the outputs file to the result. An unreadable file fails
+// an otherwise successful execution.
+func (d *outputsDir) collect(result *domain.ExecutionResult) {
+	if result.Outputs == nil {
+		result.Outputs = map[string]string{}
+	}
+	if err := readOutputsFile(d.file(), result.Outputs); err != nil && result.ExitCode == 0 {
+		result.ExitCode = 1
+		result.Error = fmt.Sprintf("Invalid outputs file: %v", err)
+	}
+	if len(result.Outputs) == 0 {
+		result.Outputs = nil
+	}
+}
+
+// parseSetOutputs adds the `::set-output name=value` lines of stdout to outputs; later lines win
+func parseSetOutputs(stdout string, outputs map[string]string) {
+	for _, line := range strings.Split(stdout, "\n") {
+		match := setOutputPattern.FindStringSubmatch(strings.TrimSuffix(line, "\r"))
+		if match != nil {
+			outputs[match[1]] = match[2]
+		}
+	}
+}
+
+// readOutputsFile adds the entries of a JSON object file to outputs. Strings are kept as they are,
+// other values as JSON. A missing or empty file holds no outputs. The file is written by the job,
+// so symlinks, hard links, special files and files over maxOutputsFileSize are rejected.
+func readOutputsFile(path string, outputs map[string]string) error {
+	file, err := openOutputsFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() || hardLinked(info) {
+		return fmt.Errorf("%s is not a regular file", outputsFileName)
+	}
+	if info.Size() > maxOutputsFileSize {
+		return fmt.Errorf("%s is larger than %d bytes", outputsFileName, maxOutputsFileSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxOutputsFileSize+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxOutputsFileSize {
+		return fmt.Errorf("%s is larger than %d bytes", outputsFileName, maxOutputsFileSize)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("expected a JSON object: %w", err)
+	}
+	for name, raw := range values {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			outputs[name] = s
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return err
+		}
+		outputs[name] = compact.String()
+	}
+	return nil
+}
+
+// validateHTTPOutputs checks the names and JSONPath expressions of HTTP outputs
+func validateHTTPOutputs(outputs map[string]string) error {
+	for name, path := range outputs {
+		if !outputNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid output name: %q", name)
+		}
+		if _, err := parseJSONPath(path); err != nil {
+			return fmt.Errorf("output %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// extractJSONOutputs evaluates the JSONPath of each output against a JSON body. Paths that match
+// nothing are left out.
+func extractJSONOutputs(paths map[string]string, body string) (map[string]string, error) {
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("response body is not valid JSON")
+	}
+
+	outputs := make(map[string]string, len(paths))
+	for name, path := range paths {
+		value, found, err := evalJSONPath(doc, path)
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", name, err)
+		}
+		if !found {
+			continue
+		}
+		if s, ok := value.(string); ok {
+			outputs[name] = s
+			continue
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", name, err)
+		}
+		outputs[name] = string(data)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,25 @@
+//go:build !unix
+
+package jobs
+
+import (
+	"fmt"
+	"os"
+)
+
+// openOutputsFile opens the outputs file, refusing symlinks
+func openOutputsFile(path string) (*os.File, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil, fmt.Errorf("%s is a symlink", outputsFileName)
+	}
+	return os.Open(path)
+}
+
+// hardLinked is not checked outside Unix
+func hardLinked(os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,78 @@
+//go:build unix
+
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestReadOutputsFileRejectsUnsafeFiles(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(t.TempDir(), "secret.json")
+	if err := os.WriteFile(secret, []byte(`{"token":"server-only"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(name string, create func(path string) error) string {
+		path := filepath.Join(dir, name)
+		if err := create(path); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	valid := write("valid.json", func(path string) error {
+		return os.WriteFile(path, []byte(`{"version":"1.4.2","size":5120}`), 0o600)
+	})
+	symlink := write("symlink.json", func(path string) error { return os.Symlink(secret, path) })
+	hardlink := write("hardlink.json", func(path string) error { return os.Link(secret, path) })
+	fifo := write("fifo.json", func(path string) error { return syscall.Mkfifo(path, 0o600) })
+	large := write("large.json", func(path string) error {
+		return os.WriteFile(path, []byte(`{"blob":"`+strings.Repeat("x", maxOutputsFileSize)+`"}`), 0o600)
+	})
+
+	outputs := map[string]string{}
+	if err := readOutputsFile(valid, outputs); err != nil || outputs["version"] != "1.4.2" || outputs["size"] != "5120" {
+		t.Fatalf("unexpected outputs %v, %v", outputs, err)
+	}
+	if err := readOutputsFile(filepath.Join(dir, "missing.json"), outputs); err != nil {
+		t.Fatalf("a missing file should hold no outputs: %v", err)
+	}
+
+	for _, path := range []string{symlink, hardlink, fifo, large} {
+		outputs := map[string]string{}
+		if err := readOutputsFile(path, outputs); err == nil || len(outputs) > 0 {
+			t.Errorf("%s: expected the file to be rejected, got %v, %v", filepath.Base(path), outputs, err)
+		}
+	}
+}
+
+func TestOutputsDirPermissions(t *testing.T) {
+	outputs, err := newOutputsDir(&shellSandbox{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outputs.close()
+	if info, err := os.Stat(outputs.path); err != nil || info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected a private outputs directory, got %v, %v", info.Mode(), err)
+	}
+
+	container, err := newContainerOutputsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := container.root
+	if info, err := os.Stat(root); err != nil || info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected the container outputs directory to live in a private directory, got %v, %v", info.Mode(), err)
+	}
+	if info, err := os.Stat(container.path); err != nil || info.Mode()&os.ModeSticky == 0 || info.Mode().Perm() != 0o733 {
+		t.Fatalf("expected a sticky, unlistable container outputs directory, got %v, %v", info.Mode(), err)
+	}
+	container.close()
+	if _, err := os.Stat(root); !os.IsNotExist(err) {
+		t.Fatalf("expected close to remove the directory, got %v", err)
+	}
+}
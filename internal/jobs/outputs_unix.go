@@ -0,0 +1,19 @@
+//go:build unix
+
+package jobs
+
+import (
+	"os"
+	"syscall"
+)
+
+// openOutputsFile opens the outputs file without following a symlink or blocking on a FIFO
+func openOutputsFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDONLY|syscall.O_NOFOLLOW|syscall.O_NONBLOCK, 0)
+}
+
+// hardLinked reports whether the file has other names, e.g. a link to a file of the server
+func hardLinked(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Nlink > 1
+}
@@ -0,0 +1,373 @@
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"github.com/meysam81/oneoff/internal/logging"
+)
+
+// Plugin protocol
+//
+// A plugin is an executable in the plugins directory. For every operation OneOff starts the
+// executable, writes newline-delimited JSON requests to its stdin and reads a single JSON
+// response line from its stdout. Anything written to stderr is treated as log output.
+//
+//	-> {"method":"describe"}
+//	<- {"type":"slack","description":"Post a Slack message","protocol_version":1,"schema":{...}}
+//
+//	-> {"method":"validate","config":{...}}
+//	<- {"error":""}
+//
+//	-> {"method":"execute","config":{...}}
+//	-> {"method":"cancel"}                     (only sent if the job is cancelled or times out)
+//	<- {"output":"...","exit_code":0,"error":""}
+//
+// The schema is optional; when given, configs are checked against it before the validate request.
+// After a cancel request, and after its response once stdin is closed, the plugin has
+// pluginCancelGracePeriod to exit before it is killed.
+// Describe and validate requests are not cancelled; the plugin is killed as soon as they time out
+// or the caller goes away.
+
+// PluginProtocolVersion is the protocol version spoken by this server
+const PluginProtocolVersion = 1
+
+// Plugin deadlines, variables so that tests can shorten them
+var (
+	pluginDescribeTimeout   = 10 * time.Second
+	pluginValidateTimeout   = 30 * time.Second
+	pluginCancelGracePeriod = 10 * time.Second
+)
+
+// pluginRequest is a message sent to a plugin
+type pluginRequest struct {
+	Method string          `json:"method"`
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// pluginResponse is the message returned by a plugin
+type pluginResponse struct {
+	Type            string          `json:"type,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	ProtocolVersion int             `json:"protocol_version,omitempty"`
+	Schema          json.RawMessage `json:"schema,omitempty"`
+	Output          string          `json:"output,omitempty"`
+	ExitCode        int             `json:"exit_code,omitempty"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// pluginInfo describes a discovered plugin
+type pluginInfo struct {
+	path        string
+	jobType     string
+	description string
+	schema      *domain.Schema
+}
+
+// PluginJob implements JobExecutor by delegating to an external plugin executable
+type PluginJob struct {
+	plugin  *pluginInfo
+	config  json.RawMessage
+	timeout int // seconds, read from the optional "timeout" field of the config
+}
+
+// LoadPlugins discovers plugin executables in dir and registers them as job types.
+// Plugins cannot override job types that are already registered.
+func LoadPlugins(ctx context.Context, registry *domain.JobRegistry, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	registered := make(map[string]bool)
+	for _, jobType := range registry.ListTypes() {
+		registered[jobType] = true
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if !isExecutable(path) {
+			logging.Debug().Str("path", path).Msg("Skipping non-executable file in plugins directory")
+			continue
+		}
+
+		plugin, err := describePlugin(ctx, path)
+		if err != nil {
+			logging.Warn().Err(err).Str("path", path).Msg("Failed to load plugin")
+			continue
+		}
+
+		if registered[plugin.jobType] {
+			logging.Warn().Str("path", path).Str("job_type", plugin.jobType).Msg("Plugin job type already registered, skipping")
+			continue
+		}
+
+		registry.Register(plugin.jobType, func(config string) (domain.JobExecutor, error) {
+			return newPluginJob(plugin, config)
+		})
+		registry.Describe(plugin.jobType, plugin.description, plugin.schema)
+		registered[plugin.jobType] = true
+
+		logging.Info().Str("path", path).Str("job_type", plugin.jobType).Msg("Registered plugin job type")
+	}
+
+	return nil
+}
+
+// describePlugin asks a plugin executable for its job type
+func describePlugin(ctx context.Context, path string) (*pluginInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, pluginDescribeTimeout)
+	defer cancel()
+
+	resp, _, err := callPlugin(ctx, path, pluginRequest{Method: "describe"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.ProtocolVersion != PluginProtocolVersion {
+		return nil, fmt.Errorf("unsupported plugin protocol version %d (expected %d)", resp.ProtocolVersion, PluginProtocolVersion)
+	}
+	if resp.Type == "" || strings.ContainsAny(resp.Type, " \t\n/") {
+		return nil, fmt.Errorf("plugin returned invalid job type %q", resp.Type)
+	}
+
+	info := &pluginInfo{
+		path:        path,
+		jobType:     resp.Type,
+		description: resp.Description,
+	}
+	if len(resp.Schema) > 0 {
+		if err := json.Unmarshal(resp.Schema, &info.schema); err != nil {
+			return nil, fmt.Errorf("plugin returned invalid schema: %w", err)
+		}
+	}
+	return info, nil
+}
+
+// newPluginJob creates a plugin-backed job from a JSON config
+func newPluginJob(plugin *pluginInfo, config string) (domain.JobExecutor, error) {
+	if !json.Valid([]byte(config)) {
+		return nil, fmt.Errorf("invalid %s job config: config must be valid JSON", plugin.jobType)
+	}
+
+	// Timeouts are enforced by OneOff, like for built-in job types
+	var common struct {
+		Timeout int `json:"timeout"`
+	}
+	_ = json.Unmarshal([]byte(config), &common)
+
+	return &PluginJob{
+		plugin:  plugin,
+		config:  json.RawMessage(config),
+		timeout: common.Timeout,
+	}, nil
+}
+
+// Type returns the job type
+func (j *PluginJob) Type() string {
+	return j.plugin.jobType
+}
+
+// Description returns job description
+func (j *PluginJob) Description() string {
+	if j.plugin.description != "" {
+		return fmt.Sprintf("%s (plugin)", j.plugin.description)
+	}
+	return fmt.Sprintf("Run %s plugin", j.plugin.jobType)
+}
+
+// Validate asks the plugin to validate the job configuration
+func (j *PluginJob) Validate() error {
+	return j.ValidateContext(context.Background())
+}
+
+// ValidateContext asks the plugin to validate the job configuration, killing it when ctx ends
+func (j *PluginJob) ValidateContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pluginValidateTimeout)
+	defer cancel()
+
+	resp, _, err := callPlugin(ctx, j.plugin.path, pluginRequest{Method: "validate", Config: j.config})
+	if err != nil {
+		return fmt.Errorf("plugin validation failed: %w", err)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
+
+// Execute runs the job through the plugin
+func (j *PluginJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// Set timeout if specified
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(j.timeout)*time.Second)
+		defer cancel()
+	}
+
+	resp, stderr, err := callPlugin(ctx, j.plugin.path, pluginRequest{Method: "execute", Config: j.config})
+
+	if ctx.Err() == context.Canceled {
+		return &domain.ExecutionResult{
+			Output:   pluginOutput(resp, stderr),
+			ExitCode: 130, // SIGINT exit code
+			Error:    "Job cancelled by user",
+		}, nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return &domain.ExecutionResult{
+			Output:   pluginOutput(resp, stderr),
+			ExitCode: 124, // Timeout exit code
+			Error:    "Plugin execution timeout",
+		}, nil
+	}
+	if err != nil {
+		return &domain.ExecutionResult{
+			Output:   pluginOutput(nil, stderr),
+			ExitCode: 1,
+			Error:    fmt.Sprintf("Plugin execution failed: %v", err),
+		}, nil
+	}
+
+	errorMsg := resp.Error
+	if resp.ExitCode != 0 && errorMsg == "" {
+		errorMsg = fmt.Sprintf("Plugin exited with code %d", resp.ExitCode)
+	}
+
+	return &domain.ExecutionResult{
+		Output:   pluginOutput(resp, stderr),
+		ExitCode: resp.ExitCode,
+		Error:    errorMsg,
+	}, nil
+}
+
+// callPlugin starts the plugin, sends the request and waits for its response.
+// When ctx ends before the plugin answers an execute request, a cancel request is sent and
+// the plugin is killed if it does not exit within the grace period. Other requests are killed
+// right away.
+func callPlugin(ctx context.Context, path string, req pluginRequest) (*pluginResponse, string, error) {
+	cmd := exec.Command(path)
+	cmd.Dir = filepath.Dir(path)
+	setSysProcAttr(cmd, nil)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open plugin stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open plugin stdout: %w", err)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, "", fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	encoder := json.NewEncoder(stdin)
+	if err := encoder.Encode(req); err != nil {
+		killProcessGroup(cmd)
+		_ = cmd.Wait()
+		return nil, stderr.String(), fmt.Errorf("failed to send request to plugin: %w", err)
+	}
+
+	type result struct {
+		resp *pluginResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := bufio.NewReader(stdout).ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			done <- result{err: fmt.Errorf("plugin did not return a response: %w", err)}
+			return
+		}
+		var resp pluginResponse
+		if err := json.Unmarshal(line, &resp); err != nil {
+			done <- result{err: fmt.Errorf("plugin returned an invalid response: %w", err)}
+			return
+		}
+		done <- result{resp: &resp}
+	}()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		if req.Method != "execute" {
+			killProcessGroup(cmd)
+			res = <-done
+			break
+		}
+		_ = encoder.Encode(pluginRequest{Method: "cancel"})
+		select {
+		case res = <-done:
+		case <-time.After(pluginCancelGracePeriod):
+			killProcessGroup(cmd)
+			res = <-done
+		}
+	}
+
+	// Closing stdin tells the plugin to exit; one that lingers after answering is killed
+	_ = stdin.Close()
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	var waitErr error
+	select {
+	case waitErr = <-exited:
+	case <-time.After(pluginCancelGracePeriod):
+		killProcessGroup(cmd)
+		waitErr = <-exited
+	}
+	if res.err != nil && waitErr != nil {
+		res.err = fmt.Errorf("%w (%v)", res.err, waitErr)
+	}
+
+	return res.resp, stderr.String(), res.err
+}
+
+// pluginOutput combines the plugin output with its logs
+func pluginOutput(resp *pluginResponse, stderr string) string {
+	output := ""
+	if resp != nil {
+		output = resp.Output
+	}
+	if stderr != "" {
+		if output != "" {
+			output += "\n\n--- STDERR ---\n"
+		}
+		output += stderr
+	}
+	return output
+}
+
+// isExecutable reports whether path is a regular file the server can execute
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,182 @@
+//go:build !windows
+
+package jobs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// testPlugin speaks the plugin protocol: it validates that "name" is not "bad", echoes its stdin
+// on execute and, for the "slow" name, waits for a cancel request before answering. The "stuck"
+// name never answers and "linger" keeps running after its response.
+const testPlugin = `#!/bin/sh
+read -r request
+case "$request" in
+*'"describe"'*)
+	echo '{"type":"greet","description":"Greet someone","protocol_version":1,"schema":{"type":"object","properties":{"name":{"type":"string"},"timeout":{"type":"integer"}},"required":["name"]}}'
+	;;
+*'"validate"'*'"name":"bad"'*)
+	echo '{"error":"name must not be bad"}'
+	;;
+*'"validate"'*'"name":"hang"'*)
+	sleep 30
+	;;
+*'"validate"'*)
+	echo '{}'
+	;;
+*'"execute"'*'"name":"slow"'*)
+	while read -r line; do
+		case "$line" in *'"cancel"'*) echo '{"output":"stopped cleanly","exit_code":1}'; exit 0 ;; esac
+	done
+	;;
+*'"execute"'*'"name":"stuck"'*)
+	sleep 30
+	;;
+*'"execute"'*'"name":"linger"'*)
+	echo '{"output":"done","exit_code":0}'
+	sleep 30
+	;;
+*'"execute"'*)
+	echo "greeting" >&2
+	echo '{"output":"hello","exit_code":0}'
+	;;
+esac
+`
+
+// loadTestPlugin registers the test plugin in a fresh registry
+func loadTestPlugin(t *testing.T) *domain.JobRegistry {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "greet"), []byte(testPlugin), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := domain.NewJobRegistry()
+	if err := LoadPlugins(context.Background(), registry, dir); err != nil {
+		t.Fatal(err)
+	}
+	return registry
+}
+
+func TestPluginProtocol(t *testing.T) {
+	registry := loadTestPlugin(t)
+
+	info := registry.TypeInfo("greet")
+	if info.Description != "Greet someone" || info.Schema == nil || len(info.Schema.Required) != 1 {
+		t.Fatalf("unexpected type info: %+v", info)
+	}
+
+	ctx := context.Background()
+	if err := registry.Validate(ctx, "greet", `{}`); err == nil || !strings.Contains(err.Error(), "config.name: is required") {
+		t.Fatalf("expected a schema error, got %v", err)
+	}
+	if err := registry.Validate(ctx, "greet", `{"name":"bad"}`); err == nil || !strings.Contains(err.Error(), "name must not be bad") {
+		t.Fatalf("expected the plugin's validation error, got %v", err)
+	}
+	if err := registry.Validate(ctx, "greet", `{"name":"ada"}`); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := registry.Create("greet", `{"name":"ada"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := job.Execute(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || result.Output != "hello\n\n--- STDERR ---\ngreeting\n" {
+		t.Fatalf("unexpected result: exit %d, output %q", result.ExitCode, result.Output)
+	}
+}
+
+func TestPluginValidateHonorsContext(t *testing.T) {
+	registry := loadTestPlugin(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := registry.Validate(ctx, "greet", `{"name":"hang"}`)
+	if err == nil {
+		t.Fatal("expected validation to fail when the caller goes away")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("validation outlived the caller's context by %s", elapsed)
+	}
+}
+
+func TestPluginCancel(t *testing.T) {
+	registry := loadTestPlugin(t)
+
+	job, err := registry.Create("greet", `{"name":"slow","timeout":1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := job.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 124 || result.Output != "stopped cleanly" {
+		t.Fatalf("expected the plugin to answer the cancel request, got exit %d, output %q", result.ExitCode, result.Output)
+	}
+}
+
+func TestPluginKilledAfterGracePeriod(t *testing.T) {
+	registry := loadTestPlugin(t)
+
+	grace := pluginCancelGracePeriod
+	pluginCancelGracePeriod = 200 * time.Millisecond
+	t.Cleanup(func() { pluginCancelGracePeriod = grace })
+
+	job, err := registry.Create("greet", `{"name":"stuck"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := job.Execute(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 130 {
+		t.Fatalf("expected a cancelled result, got exit %d, error %q", result.ExitCode, result.Error)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("plugin was not killed after the grace period, took %s", elapsed)
+	}
+}
+
+func TestPluginKilledAfterResponse(t *testing.T) {
+	registry := loadTestPlugin(t)
+
+	grace := pluginCancelGracePeriod
+	pluginCancelGracePeriod = 200 * time.Millisecond
+	t.Cleanup(func() { pluginCancelGracePeriod = grace })
+
+	job, err := registry.Create("greet", `{"name":"linger"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	result, err := job.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || result.Output != "done" {
+		t.Fatalf("expected the plugin's response, got exit %d, output %q, error %q", result.ExitCode, result.Output, result.Error)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("plugin was not killed after answering, took %s", elapsed)
+	}
+}
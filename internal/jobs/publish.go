@@ -0,0 +1,300 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const publishDefaultTimeout = 30 * time.Second
+
+// PublishJob implements JobExecutor for publishing messages to NATS and AMQP brokers
+type PublishJob struct {
+	config *domain.PublishJobConfig
+}
+
+// NewPublishJob creates a new publish job
+func NewPublishJob(config string) (domain.JobExecutor, error) {
+	cfg, err := domain.ParsePublishJobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid publish job config: %w", err)
+	}
+
+	return &PublishJob{config: cfg}, nil
+}
+
+// Type returns the job type
+func (j *PublishJob) Type() string {
+	return "publish"
+}
+
+// Description returns job description
+func (j *PublishJob) Description() string {
+	if j.config.Broker == "amqp" {
+		return fmt.Sprintf("Publish to AMQP exchange %q with routing key %q", j.config.Exchange, j.config.RoutingKey)
+	}
+	return fmt.Sprintf("Publish to NATS subject %s", j.config.Subject)
+}
+
+// Validate validates the job configuration
+func (j *PublishJob) Validate() error {
+	if j.config.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	switch j.config.Broker {
+	case "nats":
+		if j.config.Subject == "" {
+			return fmt.Errorf("subject is required for NATS")
+		}
+		if strings.ContainsAny(j.config.Subject, " \t\r\n") {
+			return fmt.Errorf("subject cannot contain whitespace")
+		}
+	case "amqp":
+		if j.config.Exchange == "" && j.config.RoutingKey == "" {
+			return fmt.Errorf("routing_key is required when publishing to the default exchange")
+		}
+		if j.config.TokenSecret != "" {
+			return fmt.Errorf("token_secret is only supported for NATS")
+		}
+		if j.config.Properties != nil && j.config.Properties.Priority > 9 {
+			return fmt.Errorf("priority must be between 0 and 9")
+		}
+	default:
+		return fmt.Errorf("invalid broker: %s (must be nats or amqp)", j.config.Broker)
+	}
+
+	if j.config.PasswordSecret != "" && j.config.Username == "" {
+		return fmt.Errorf("username is required with password_secret")
+	}
+
+	return nil
+}
+
+// Execute publishes the message
+func (j *PublishJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Set timeout if specified
+	timeout := publishDefaultTimeout
+	if j.config.Timeout > 0 {
+		timeout = time.Duration(j.config.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var output string
+	var err error
+	if j.config.Broker == "nats" {
+		output, err = j.publishNATS(ctx)
+	} else {
+		output, err = j.publishAMQP(ctx)
+	}
+
+	if ctx.Err() == context.Canceled {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 130,
+			Error:    "Job cancelled by user",
+		}, nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 124,
+			Error:    "Publish timeout",
+		}, nil
+	}
+	if err != nil {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 1,
+			Error:    fmt.Sprintf("Publish failed: %v", err),
+		}, nil
+	}
+
+	return &domain.ExecutionResult{
+		Output:   output,
+		ExitCode: 0,
+	}, nil
+}
+
+// publishNATS publishes to a NATS subject, optionally through JetStream
+func (j *PublishJob) publishNATS(ctx context.Context) (string, error) {
+	opts := []nats.Option{nats.Name("oneoff")}
+	if deadline, ok := ctx.Deadline(); ok {
+		opts = append(opts, nats.Timeout(time.Until(deadline)))
+	}
+	if j.config.Username != "" {
+		password, err := j.password()
+		if err != nil {
+			return "", err
+		}
+		opts = append(opts, nats.UserInfo(j.config.Username, password))
+	}
+	if j.config.TokenSecret != "" {
+		token, err := domain.ResolveSecret(j.config.TokenSecret)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve token: %w", err)
+		}
+		opts = append(opts, nats.Token(token))
+	}
+
+	nc, err := nats.Connect(j.config.URL, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect: %w", err)
+	}
+	defer nc.Close()
+
+	msg := nats.NewMsg(j.config.Subject)
+	msg.Data = []byte(j.config.Payload)
+	for key, value := range j.config.Headers {
+		msg.Header.Set(key, value)
+	}
+
+	output := fmt.Sprintf("Connected to %s\n", nc.ConnectedUrlRedacted())
+
+	if j.config.JetStream {
+		js, err := jetstream.New(nc)
+		if err != nil {
+			return output, fmt.Errorf("failed to create JetStream context: %w", err)
+		}
+		ack, err := js.PublishMsg(ctx, msg)
+		if err != nil {
+			return output, fmt.Errorf("JetStream publish not acknowledged: %w", err)
+		}
+		output += fmt.Sprintf("Published %d bytes to %s\n", len(msg.Data), j.config.Subject)
+		output += fmt.Sprintf("JetStream ack: stream=%s sequence=%d duplicate=%t\n", ack.Stream, ack.Sequence, ack.Duplicate)
+		return output, nil
+	}
+
+	if err := nc.PublishMsg(msg); err != nil {
+		return output, fmt.Errorf("failed to publish: %w", err)
+	}
+	// A flush round-trip confirms the server has received the message
+	if err := nc.FlushWithContext(ctx); err != nil {
+		return output, fmt.Errorf("failed to flush: %w", err)
+	}
+	output += fmt.Sprintf("Published %d bytes to %s\n", len(msg.Data), j.config.Subject)
+	output += "Server acknowledged flush\n"
+	return output, nil
+}
+
+// publishAMQP publishes to an AMQP 0-9-1 exchange, waiting for publisher confirms
+func (j *PublishJob) publishAMQP(ctx context.Context) (string, error) {
+	uri, err := amqp.ParseURI(j.config.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if j.config.Username != "" {
+		password, err := j.password()
+		if err != nil {
+			return "", err
+		}
+		uri.Username = j.config.Username
+		uri.Password = password
+	}
+
+	conn, err := amqp.DialConfig(uri.String(), amqp.Config{
+		Properties: amqp.NewConnectionProperties(),
+		Dial: func(network, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to connect: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return "", fmt.Errorf("failed to open channel: %w", err)
+	}
+
+	confirm := j.config.Confirm == nil || *j.config.Confirm
+	if confirm {
+		if err := ch.Confirm(false); err != nil {
+			return "", fmt.Errorf("broker does not support publisher confirms: %w", err)
+		}
+	}
+	returns := ch.NotifyReturn(make(chan amqp.Return, 1))
+
+	publishing := amqp.Publishing{
+		Body:      []byte(j.config.Payload),
+		Timestamp: time.Now().UTC(),
+	}
+	if len(j.config.Headers) > 0 {
+		publishing.Headers = amqp.Table{}
+		for key, value := range j.config.Headers {
+			publishing.Headers[key] = value
+		}
+	}
+	if p := j.config.Properties; p != nil {
+		publishing.ContentType = p.ContentType
+		publishing.Priority = p.Priority
+		publishing.CorrelationId = p.CorrelationID
+		publishing.ReplyTo = p.ReplyTo
+		publishing.Expiration = p.Expiration
+		publishing.MessageId = p.MessageID
+		publishing.Type = p.Type
+		publishing.AppId = p.AppID
+		if p.Persistent {
+			publishing.DeliveryMode = amqp.Persistent
+		}
+	}
+
+	output := fmt.Sprintf("Connected to %s:%d%s\n", uri.Host, uri.Port, uri.Vhost)
+
+	deferred, err := ch.PublishWithDeferredConfirmWithContext(ctx, j.config.Exchange, j.config.RoutingKey, j.config.Mandatory, false, publishing)
+	if err != nil {
+		return output, fmt.Errorf("failed to publish: %w", err)
+	}
+	output += fmt.Sprintf("Published %d bytes to exchange %q with routing key %q\n", len(publishing.Body), j.config.Exchange, j.config.RoutingKey)
+
+	if !confirm {
+		return output, nil
+	}
+
+	acked, err := deferred.WaitContext(ctx)
+	if err != nil {
+		return output, fmt.Errorf("failed to wait for confirm: %w", err)
+	}
+	if !acked {
+		return output + "Broker nacked the message\n", fmt.Errorf("message was not acknowledged by the broker")
+	}
+	output += fmt.Sprintf("Broker confirmed delivery tag %d\n", deferred.DeliveryTag)
+
+	// The broker sends basic.return before the confirm, so any return has arrived by now
+	select {
+	case ret := <-returns:
+		return output + fmt.Sprintf("Message returned: %d %s\n", ret.ReplyCode, ret.ReplyText),
+			fmt.Errorf("message could not be routed: %s", ret.ReplyText)
+	default:
+	}
+
+	return output, nil
+}
+
+// password resolves the broker password secret
+func (j *PublishJob) password() (string, error) {
+	if j.config.PasswordSecret == "" {
+		return "", nil
+	}
+	password, err := domain.ResolveSecret(j.config.PasswordSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve password: %w", err)
+	}
+	return password, nil
+}
@@ -0,0 +1,109 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	natsserver "github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// startTestNATSServer runs an embedded NATS server with JetStream enabled
+func startTestNATSServer(t *testing.T) *natsserver.Server {
+	t.Helper()
+	server, err := natsserver.NewServer(&natsserver.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Start()
+	if !server.ReadyForConnections(10 * time.Second) {
+		t.Fatal("NATS server did not start")
+	}
+	t.Cleanup(server.Shutdown)
+	return server
+}
+
+func TestPublishNATS(t *testing.T) {
+	server := startTestNATSServer(t)
+	url := server.ClientURL()
+	ctx := context.Background()
+
+	nc, err := nats.Connect(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := nc.SubscribeSync("events.ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	order := `{"broker":"nats","url":"` + url + `","subject":"orders.created","payload":"{\"id\":42}",` +
+		`"headers":{"Nats-Msg-Id":"order-42","X-Source":"oneoff"},"jetstream":true}`
+
+	// Cases run in order against the same server
+	tests := []struct {
+		name     string
+		config   string
+		exitCode int
+		contains string // expected in the output, or in the error on failure
+	}{
+		{"jetstream", order, 0, "JetStream ack: stream=ORDERS sequence=1 duplicate=false"},
+		{"jetstream duplicate", order, 0, "sequence=1 duplicate=true"},
+		{"jetstream without stream", `{"broker":"nats","url":"` + url + `","subject":"nowhere.created","payload":"lost","jetstream":true,"timeout":5}`, 1, "not acknowledged"},
+		{"core headers", `{"broker":"nats","url":"` + url + `","subject":"events.ping","payload":"ping","headers":{"X-Attempt":"1"}}`, 0, "Server acknowledged flush"},
+	}
+	for _, tt := range tests {
+		job, err := NewPublishJob(tt.config)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		result, err := job.Execute(ctx)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		message := result.Output
+		if result.ExitCode != 0 {
+			message = result.Error
+		}
+		if result.ExitCode != tt.exitCode || !strings.Contains(message, tt.contains) {
+			t.Errorf("%s: exit %d, output %q, error %q; want exit %d with %q", tt.name, result.ExitCode, result.Output, result.Error, tt.exitCode, tt.contains)
+		}
+	}
+
+	msg, err := stream.GetMsg(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.Data) != `{"id":42}` || msg.Header.Get("X-Source") != "oneoff" || msg.Header.Get("Nats-Msg-Id") != "order-42" {
+		t.Fatalf("unexpected stored message: %q with headers %v", msg.Data, msg.Header)
+	}
+	coreMsg, err := sub.NextMsg(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(coreMsg.Data) != "ping" || coreMsg.Header.Get("X-Attempt") != "1" {
+		t.Fatalf("unexpected message: %q with headers %v", coreMsg.Data, coreMsg.Header)
+	}
+}
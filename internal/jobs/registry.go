@@ -2,9 +2,89 @@ package jobs
 
 import "github.com/meysam81/oneoff/internal/domain"
 
+// Options holds admin-level settings for built-in job types
+type Options struct {
+	// FilesAllowedRoots are the only directories files jobs, and other jobs reading host paths, may touch
+	FilesAllowedRoots []string
+	// ShellAllowedInterpreters are the interpreters shell jobs may use
+	ShellAllowedInterpreters []string
+	// ShellInheritEnv are the server environment variables passed to shell jobs
+	ShellInheritEnv []string
+	// ShellAllowedRunAs are the users shell jobs may run as
+	ShellAllowedRunAs []string
+	// ShellCgroupParent is the cgroup v2 directory for shell jobs with memory, CPU or process caps
+	ShellCgroupParent string
+	// S3AmbientCredentials lets S3 jobs without credential secrets use the server's own AWS credentials
+	S3AmbientCredentials bool
+	// BackupUploadTargets are the endpoint/bucket pairs oneoff-backup jobs may upload the database to
+	BackupUploadTargets []string
+	// DBPath is the OneOff database snapshotted by oneoff-backup jobs
+	DBPath string
+}
+
 // RegisterJobTypes registers all built-in job types
-func RegisterJobTypes(registry *domain.JobRegistry) {
+func RegisterJobTypes(registry *domain.JobRegistry, opts Options) {
 	registry.Register("http", NewHTTPJob)
-	registry.Register("shell", NewShellJob)
+	registry.Register("shell", func(config string) (domain.JobExecutor, error) {
+		return NewShellJob(config, ShellPolicy{
+			AllowedInterpreters: opts.ShellAllowedInterpreters,
+			InheritEnv:          opts.ShellInheritEnv,
+			AllowedRunAs:        opts.ShellAllowedRunAs,
+			CgroupParent:        opts.ShellCgroupParent,
+		})
+	})
 	registry.Register("docker", NewDockerJob)
+	registry.Register("grpc", func(config string) (domain.JobExecutor, error) {
+		return NewGRPCJob(config, opts.FilesAllowedRoots)
+	})
+	registry.Register("ssh", func(config string) (domain.JobExecutor, error) {
+		return NewSSHJob(config, opts.FilesAllowedRoots)
+	})
+	registry.Register("wasm", NewWasmJob)
+	registry.Register("sensor", func(config string) (domain.JobExecutor, error) {
+		return NewSensorJob(config, opts.FilesAllowedRoots)
+	})
+	registry.Register("check", NewCheckJob)
+	registry.Register("publish", NewPublishJob)
+	s3Policy := S3Policy{
+		AllowedRoots:       opts.FilesAllowedRoots,
+		AmbientCredentials: opts.S3AmbientCredentials,
+		BackupTargets:      opts.BackupUploadTargets,
+	}
+	registry.Register("s3", func(config string) (domain.JobExecutor, error) {
+		return NewS3Job(config, s3Policy)
+	})
+	registry.Register("files", func(config string) (domain.JobExecutor, error) {
+		return NewFilesJob(config, opts.FilesAllowedRoots)
+	})
+	registry.Register("oneoff-backup", func(config string) (domain.JobExecutor, error) {
+		return NewOneOffBackupJob(config, opts.DBPath, opts.FilesAllowedRoots, s3Policy)
+	})
+
+	describeJobTypes(registry)
+}
+
+// describeJobTypes sets the description and config schema of the built-in job types
+func describeJobTypes(registry *domain.JobRegistry) {
+	types := []struct {
+		name        string
+		description string
+		config      any
+	}{
+		{"http", "Send an HTTP request", domain.HTTPJobConfig{}},
+		{"shell", "Run a shell script or a sequence of scripts", domain.ShellJobConfig{}},
+		{"docker", "Run a command in a Docker container", domain.DockerJobConfig{}},
+		{"grpc", "Make a unary gRPC call", domain.GRPCJobConfig{}},
+		{"ssh", "Run a command or script on a remote host over SSH", domain.SSHJobConfig{}},
+		{"wasm", "Run a sandboxed WebAssembly (WASI) module", domain.WasmJobConfig{}},
+		{"sensor", "Wait until a file, port, URL or query condition is met", domain.SensorJobConfig{}},
+		{"check", "Check a TLS certificate or DNS record", domain.CheckJobConfig{}},
+		{"publish", "Publish a message to NATS or AMQP", domain.PublishJobConfig{}},
+		{"s3", "Transfer, copy, delete or presign objects in S3-compatible storage", domain.S3JobConfig{}},
+		{"files", "Copy, move, delete, archive or checksum local files", domain.FilesJobConfig{}},
+		{"oneoff-backup", "Back up the OneOff database", domain.OneOffBackupJobConfig{}},
+	}
+	for _, t := range types {
+		registry.Describe(t.name, t.description, domain.SchemaFor(t.config, t.description))
+	}
 }
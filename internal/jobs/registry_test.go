@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+func TestRegistryValidatesRenderedTemplates(t *testing.T) {
+	root := t.TempDir()
+	registry := domain.NewJobRegistry()
+	RegisterJobTypes(registry, Options{FilesAllowedRoots: []string{root}})
+
+	tests := []struct {
+		name    string
+		jobType string
+		config  string
+		params  []domain.JobParameter
+		message string
+	}{
+		{"path outside roots", "files", `{"operation":"delete","source":"{{ .Params.dir }}"}`,
+			[]domain.JobParameter{{Name: "dir", Type: domain.ParameterTypeString, Default: "/etc"}}, "outside the allowed roots"},
+		{"enum", "files", `{"operation":"{{ .Params.op }}","source":"` + root + `"}`,
+			[]domain.JobParameter{{Name: "op", Type: domain.ParameterTypeString, Default: "shred"}}, "config.operation: must be one of"},
+		{"sample value", "http", `{"url":"https://{{ .Params.host }}/health"}`,
+			[]domain.JobParameter{{Name: "host", Type: domain.ParameterTypeString, Required: true}}, ""},
+		{"valid", "files", `{"operation":"checksum","source":"{{ .Params.dir }}"}`,
+			[]domain.JobParameter{{Name: "dir", Type: domain.ParameterTypeString, Default: root}}, ""},
+	}
+	for _, tt := range tests {
+		data := &domain.TemplateData{Params: domain.SampleParams(tt.params)}
+		config, err := domain.RenderConfig(tt.config, data, true)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		err = registry.Validate(context.Background(), tt.jobType, config)
+		if tt.message == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.message != "" && (err == nil || !strings.Contains(err.Error(), tt.message)) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.message, err)
+		}
+	}
+}
+
+// setTestSecretPolicy lets env:ONEOFF_SECRET_* references resolve until the test ends
+func setTestSecretPolicy(t *testing.T) {
+	t.Helper()
+	domain.SetSecretPolicy(domain.SecretPolicy{AllowedEnv: []string{"ONEOFF_SECRET_*"}})
+	t.Cleanup(func() { domain.SetSecretPolicy(domain.SecretPolicy{}) })
+}
@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// checkAllowedPath checks that a configured host path is absolute and inside the allowed roots
+// (FILES_ALLOWED_ROOTS). Symlinks are only resolved when the job runs, see resolveAllowedPath.
+func checkAllowedPath(field, path string, roots []string) error {
+	if len(roots) == 0 {
+		return domain.NewPolicyViolation("%s: host paths are disabled: no allowed roots configured (FILES_ALLOWED_ROOTS)", field)
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%s: path must be absolute: %s", field, path)
+	}
+	if !withinRoots(filepath.Clean(path), roots) {
+		return domain.NewPolicyViolation("%s: path is outside the allowed roots: %s", field, path)
+	}
+	return nil
+}
+
+// resolveAllowedPath resolves the symlinks of a host path and checks that it still lies inside the
+// allowed roots. Paths that do not exist yet are resolved through their deepest existing ancestor.
+func resolveAllowedPath(field, path string, roots []string) (string, error) {
+	resolved, err := resolveDestination(path)
+	if err != nil {
+		return "", fmt.Errorf("%s not accessible: %w", field, err)
+	}
+	if !withinRoots(resolved, resolvedRoots(roots)) {
+		return "", domain.NewPolicyViolation("%s resolves outside the allowed roots: %s", field, path)
+	}
+	return resolved, nil
+}
+
+// resolvedRoots returns the allowed roots with symlinks resolved
+func resolvedRoots(roots []string) []string {
+	resolved := make([]string, 0, len(roots))
+	for _, root := range roots {
+		path, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			continue
+		}
+		resolved = append(resolved, path)
+	}
+	return resolved
+}
+
+// withinRoots reports whether path is one of the roots or inside one
+func withinRoots(path string, roots []string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(filepath.Clean(root), path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDestination resolves symlinks in the deepest existing ancestor of path
+func resolveDestination(path string) (string, error) {
+	path = filepath.Clean(path)
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		missing = append([]string{filepath.Base(path)}, missing...)
+		path = parent
+	}
+}
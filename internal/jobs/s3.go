@@ -0,0 +1,444 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const (
+	s3DefaultEndpoint   = "s3.amazonaws.com"
+	s3DefaultPartSizeMB = 16
+	s3DefaultExpiresIn  = 3600
+	s3MaxExpiresIn      = 7 * 24 * 3600
+)
+
+// S3Policy holds the admin-level restrictions applied to S3 jobs
+type S3Policy struct {
+	// AllowedRoots are the only directories objects may be uploaded from or downloaded to
+	AllowedRoots []string
+	// AmbientCredentials lets jobs without credential secrets use the server's own AWS environment,
+	// credentials file and instance role
+	AmbientCredentials bool
+	// BackupTargets are the endpoint/bucket pairs oneoff-backup jobs may upload to; empty disables uploads
+	BackupTargets []string
+}
+
+// S3Job implements JobExecutor for S3-compatible object storage operations
+type S3Job struct {
+	config *domain.S3JobConfig
+	policy S3Policy
+}
+
+// NewS3Job creates a new S3 job restricted by the policy
+func NewS3Job(config string, policy S3Policy) (domain.JobExecutor, error) {
+	cfg, err := domain.ParseS3JobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 job config: %w", err)
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = s3DefaultEndpoint
+	}
+	if cfg.PartSizeMB == 0 {
+		cfg.PartSizeMB = s3DefaultPartSizeMB
+	}
+	if cfg.ExpiresIn == 0 {
+		cfg.ExpiresIn = s3DefaultExpiresIn
+	}
+	if cfg.Method == "" {
+		cfg.Method = "GET"
+	}
+	if cfg.SourceBucket == "" {
+		cfg.SourceBucket = cfg.Bucket
+	}
+
+	return &S3Job{config: cfg, policy: policy}, nil
+}
+
+// Type returns the job type
+func (j *S3Job) Type() string {
+	return "s3"
+}
+
+// Description returns job description
+func (j *S3Job) Description() string {
+	switch j.config.Operation {
+	case "put":
+		return fmt.Sprintf("Upload to s3://%s/%s", j.config.Bucket, j.config.Key)
+	case "get":
+		return fmt.Sprintf("Download s3://%s/%s", j.config.Bucket, j.config.Key)
+	case "copy":
+		return fmt.Sprintf("Copy s3://%s/%s to s3://%s/%s", j.config.SourceBucket, j.config.SourceKey, j.config.Bucket, j.config.Key)
+	case "delete":
+		return fmt.Sprintf("Delete s3://%s/%s*", j.config.Bucket, j.config.Prefix)
+	case "presign":
+		return fmt.Sprintf("Presign %s s3://%s/%s", j.config.Method, j.config.Bucket, j.config.Key)
+	}
+	return "S3 operation"
+}
+
+// Validate validates the job configuration
+func (j *S3Job) Validate() error {
+	if j.config.Bucket == "" {
+		return fmt.Errorf("bucket is required")
+	}
+	if strings.Contains(j.config.Endpoint, "://") {
+		return fmt.Errorf("endpoint must be host[:port] without a scheme")
+	}
+	if err := checkS3Credentials("", j.config.AccessKeyIDSecret, j.config.SecretAccessKeySecret, j.policy); err != nil {
+		return err
+	}
+
+	switch j.config.Operation {
+	case "put":
+		if j.config.Key == "" {
+			return fmt.Errorf("key is required for put")
+		}
+		if (j.config.Source == "") == (j.config.SourceURL == "") {
+			return fmt.Errorf("exactly one of source or source_url is required for put")
+		}
+		if j.config.Source != "" {
+			if err := checkAllowedPath("source", j.config.Source, j.policy.AllowedRoots); err != nil {
+				return err
+			}
+		}
+		if j.config.PartSizeMB < 5 {
+			return fmt.Errorf("part_size_mb must be at least 5")
+		}
+	case "get":
+		if j.config.Key == "" || j.config.Destination == "" {
+			return fmt.Errorf("key and destination are required for get")
+		}
+		if err := checkAllowedPath("destination", j.config.Destination, j.policy.AllowedRoots); err != nil {
+			return err
+		}
+	case "copy":
+		if j.config.Key == "" || j.config.SourceKey == "" {
+			return fmt.Errorf("key and source_key are required for copy")
+		}
+	case "delete":
+		if j.config.Prefix == "" && !j.config.AllowEmptyPrefix {
+			return fmt.Errorf("prefix is required for delete (set allow_empty_prefix to delete from the whole bucket)")
+		}
+		if j.config.OlderThan != "" {
+			if d, err := time.ParseDuration(j.config.OlderThan); err != nil || d < 0 {
+				return fmt.Errorf("older_than must be a positive duration, e.g. 720h")
+			}
+		}
+	case "presign":
+		if j.config.Key == "" {
+			return fmt.Errorf("key is required for presign")
+		}
+		if j.config.Method != "GET" && j.config.Method != "PUT" {
+			return fmt.Errorf("invalid method: %s (must be GET or PUT)", j.config.Method)
+		}
+		if j.config.ExpiresIn < 1 || j.config.ExpiresIn > s3MaxExpiresIn {
+			return fmt.Errorf("expires_in must be between 1 and %d seconds", s3MaxExpiresIn)
+		}
+	default:
+		return fmt.Errorf("invalid operation: %s (must be put, get, copy, delete or presign)", j.config.Operation)
+	}
+
+	return nil
+}
+
+// Execute runs the S3 operation
+func (j *S3Job) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Set timeout if specified
+	if j.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(j.config.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	client, err := j.client()
+	if err != nil {
+		return nil, err
+	}
+
+	var output string
+	switch j.config.Operation {
+	case "put":
+		output, err = j.put(ctx, client)
+	case "get":
+		output, err = j.get(ctx, client)
+	case "copy":
+		output, err = j.copy(ctx, client)
+	case "delete":
+		output, err = j.delete(ctx, client)
+	case "presign":
+		output, err = j.presign(ctx, client)
+	}
+
+	if ctx.Err() == context.Canceled {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 130,
+			Error:    "Job cancelled by user",
+		}, nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 124,
+			Error:    "S3 operation timeout",
+		}, nil
+	}
+	if err != nil {
+		return &domain.ExecutionResult{
+			Output:   output,
+			ExitCode: 1,
+			Error:    fmt.Sprintf("S3 %s failed: %v", j.config.Operation, err),
+		}, nil
+	}
+
+	return &domain.ExecutionResult{
+		Output:   output,
+		ExitCode: 0,
+	}, nil
+}
+
+// client builds a MinIO client for the configured endpoint and credentials
+func (j *S3Job) client() (*minio.Client, error) {
+	var creds *credentials.Credentials
+	if j.config.AccessKeyIDSecret != "" {
+		accessKeyID, err := domain.ResolveSecret(j.config.AccessKeyIDSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve access key ID: %w", err)
+		}
+		secretAccessKey, err := domain.ResolveSecret(j.config.SecretAccessKeySecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secret access key: %w", err)
+		}
+		sessionToken := ""
+		if j.config.SessionTokenSecret != "" {
+			if sessionToken, err = domain.ResolveSecret(j.config.SessionTokenSecret); err != nil {
+				return nil, fmt.Errorf("failed to resolve session token: %w", err)
+			}
+		}
+		creds = credentials.NewStaticV4(accessKeyID, secretAccessKey, sessionToken)
+	} else {
+		if !j.policy.AmbientCredentials {
+			return nil, domain.NewPolicyViolation("credential secrets are required (S3_AMBIENT_CREDENTIALS is disabled)")
+		}
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{},
+		})
+	}
+
+	opts := &minio.Options{
+		Creds:  creds,
+		Secure: !j.config.Insecure,
+		Region: j.config.Region,
+	}
+	if j.config.PathStyle {
+		opts.BucketLookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(j.config.Endpoint, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return client, nil
+}
+
+// put uploads a local file or a URL, using multipart uploads for large objects
+func (j *S3Job) put(ctx context.Context, client *minio.Client) (string, error) {
+	opts := minio.PutObjectOptions{
+		ContentType: j.config.ContentType,
+		PartSize:    uint64(j.config.PartSizeMB) << 20,
+	}
+
+	var info minio.UploadInfo
+	var err error
+	source := j.config.Source
+
+	if j.config.Source != "" {
+		var path string
+		if path, err = resolveAllowedPath("source", j.config.Source, j.policy.AllowedRoots); err != nil {
+			return "", err
+		}
+		info, err = client.FPutObject(ctx, j.config.Bucket, j.config.Key, path, opts)
+	} else {
+		source = j.config.SourceURL
+		var req *http.Request
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, j.config.SourceURL, nil)
+		if err != nil {
+			return "", fmt.Errorf("invalid source_url: %w", err)
+		}
+		var resp *http.Response
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch source_url: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to fetch source_url: unexpected status %d", resp.StatusCode)
+		}
+		if opts.ContentType == "" {
+			opts.ContentType = resp.Header.Get("Content-Type")
+		}
+		// ContentLength is -1 when unknown, which makes the client stream multipart uploads
+		info, err = client.PutObject(ctx, j.config.Bucket, j.config.Key, resp.Body, resp.ContentLength, opts)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Uploaded %s to s3://%s/%s\nObjects: 1\nBytes: %d\nETag: %s\n",
+		source, info.Bucket, info.Key, info.Size, info.ETag), nil
+}
+
+// get downloads an object to a local file
+func (j *S3Job) get(ctx context.Context, client *minio.Client) (string, error) {
+	path, err := resolveAllowedPath("destination", j.config.Destination, j.policy.AllowedRoots)
+	if err != nil {
+		return "", err
+	}
+	if err := client.FGetObject(ctx, j.config.Bucket, j.config.Key, path, minio.GetObjectOptions{}); err != nil {
+		return "", err
+	}
+	stat, err := client.StatObject(ctx, j.config.Bucket, j.config.Key, minio.StatObjectOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Downloaded s3://%s/%s to %s\nObjects: 1\nBytes: %d\nETag: %s\n",
+		j.config.Bucket, j.config.Key, j.config.Destination, stat.Size, stat.ETag), nil
+}
+
+// copy copies an object server-side
+func (j *S3Job) copy(ctx context.Context, client *minio.Client) (string, error) {
+	info, err := client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: j.config.Bucket, Object: j.config.Key},
+		minio.CopySrcOptions{Bucket: j.config.SourceBucket, Object: j.config.SourceKey},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	stat, err := client.StatObject(ctx, j.config.Bucket, j.config.Key, minio.StatObjectOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Copied s3://%s/%s to s3://%s/%s\nObjects: 1\nBytes: %d\nETag: %s\n",
+		j.config.SourceBucket, j.config.SourceKey, info.Bucket, info.Key, stat.Size, info.ETag), nil
+}
+
+// delete removes every object under the prefix, optionally only those older than a cutoff
+func (j *S3Job) delete(ctx context.Context, client *minio.Client) (string, error) {
+	var cutoff time.Time
+	if j.config.OlderThan != "" {
+		age, _ := time.ParseDuration(j.config.OlderThan)
+		cutoff = time.Now().Add(-age)
+	}
+
+	sizes := make(map[string]int64)
+	listErr := make(chan error, 1)
+	toDelete := make(chan minio.ObjectInfo)
+	listed := make(chan struct{})
+
+	go func() {
+		defer close(listed)
+		defer close(toDelete)
+		for object := range client.ListObjects(ctx, j.config.Bucket, minio.ListObjectsOptions{
+			Prefix:    j.config.Prefix,
+			Recursive: true,
+		}) {
+			if object.Err != nil {
+				listErr <- object.Err
+				return
+			}
+			if !cutoff.IsZero() && !object.LastModified.Before(cutoff) {
+				continue
+			}
+			sizes[object.Key] = object.Size
+			select {
+			case toDelete <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var failed []string
+	var firstErr error
+	for removeErr := range client.RemoveObjects(ctx, j.config.Bucket, toDelete, minio.RemoveObjectsOptions{}) {
+		failed = append(failed, removeErr.ObjectName)
+		if firstErr == nil {
+			firstErr = fmt.Errorf("failed to delete %s: %w", removeErr.ObjectName, removeErr.Err)
+		}
+	}
+
+	// RemoveObjects only stops early when ctx ends, which also stops the listing goroutine
+	<-listed
+	for _, name := range failed {
+		delete(sizes, name)
+	}
+	var deletedBytes int64
+	for _, size := range sizes {
+		deletedBytes += size
+	}
+
+	output := fmt.Sprintf("Deleted objects under s3://%s/%s", j.config.Bucket, j.config.Prefix)
+	if !cutoff.IsZero() {
+		output += fmt.Sprintf(" older than %s", j.config.OlderThan)
+	}
+	output += fmt.Sprintf("\nObjects: %d\nBytes: %d\n", len(sizes), deletedBytes)
+	if len(failed) > 0 {
+		output += fmt.Sprintf("Failed: %d\n", len(failed))
+	}
+
+	select {
+	case err := <-listErr:
+		return output, fmt.Errorf("failed to list objects: %w", err)
+	default:
+	}
+	return output, firstErr
+}
+
+// checkS3Credentials checks that credential secrets come in pairs and are set unless the policy
+// allows the server's own credentials. prefix names the config section in errors.
+func checkS3Credentials(prefix, accessKeyIDSecret, secretAccessKeySecret string, policy S3Policy) error {
+	if (accessKeyIDSecret == "") != (secretAccessKeySecret == "") {
+		return fmt.Errorf("%saccess_key_id_secret and %ssecret_access_key_secret must be provided together", prefix, prefix)
+	}
+	if accessKeyIDSecret == "" && !policy.AmbientCredentials {
+		return domain.NewPolicyViolation("%saccess_key_id_secret and %ssecret_access_key_secret are required: jobs may not use the server's own AWS credentials (S3_AMBIENT_CREDENTIALS)", prefix, prefix)
+	}
+	return nil
+}
+
+// presign generates a pre-signed URL for the object
+func (j *S3Job) presign(ctx context.Context, client *minio.Client) (string, error) {
+	expiry := time.Duration(j.config.ExpiresIn) * time.Second
+
+	var presigned *url.URL
+	var err error
+	if j.config.Method == "PUT" {
+		presigned, err = client.PresignedPutObject(ctx, j.config.Bucket, j.config.Key, expiry)
+	} else {
+		presigned, err = client.PresignedGetObject(ctx, j.config.Bucket, j.config.Key, expiry, nil)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s\n\nMethod: %s\nExpires: %s\n",
+		presigned.String(), j.config.Method, time.Now().Add(expiry).UTC().Format(time.RFC3339)), nil
+}
@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// startTestS3Server runs an in-memory S3 stand-in with one bucket and returns its endpoint
+func startTestS3Server(t *testing.T, bucket string) string {
+	t.Helper()
+	backend := s3mem.New()
+	if err := backend.CreateBucket(bucket); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(gofakes3.New(backend).Server())
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// testS3Credentials are the secret references of the test credentials, set with t.Setenv
+const testS3Credentials = `"access_key_id_secret":"env:ONEOFF_SECRET_TEST_S3_ACCESS_KEY_ID","secret_access_key_secret":"env:ONEOFF_SECRET_TEST_S3_SECRET_ACCESS_KEY"`
+
+func TestS3JobTransfersWithinAllowedRoots(t *testing.T) {
+	t.Setenv("ONEOFF_SECRET_TEST_S3_ACCESS_KEY_ID", "test")
+	t.Setenv("ONEOFF_SECRET_TEST_S3_SECRET_ACCESS_KEY", "test")
+	setTestSecretPolicy(t)
+
+	endpoint := startTestS3Server(t, "backups")
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "dump.sql"), []byte("select 1;"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Steps run in order against the same bucket
+	tests := []struct {
+		name     string
+		config   string
+		contains string
+		download string // file expected to hold the dump afterwards
+	}{
+		{"put", `"operation":"put","key":"db/dump.sql","source":"` + filepath.Join(root, "dump.sql") + `"`, "Bytes: 9", ""},
+		{"get", `"operation":"get","key":"db/dump.sql","destination":"` + filepath.Join(root, "restore", "dump.sql") + `"`, "", filepath.Join(root, "restore", "dump.sql")},
+		{"delete", `"operation":"delete","prefix":"db/"`, "Objects: 1", ""},
+	}
+	for _, tt := range tests {
+		config := `{"endpoint":"` + endpoint + `","insecure":true,"path_style":true,"bucket":"backups",` + testS3Credentials + `,` + tt.config + `}`
+		job, err := NewS3Job(config, S3Policy{AllowedRoots: []string{root}})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		result, err := job.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if result.ExitCode != 0 || !strings.Contains(result.Output, tt.contains) {
+			t.Fatalf("%s: exit %d, error %q, output %q; want output containing %q", tt.name, result.ExitCode, result.Error, result.Output, tt.contains)
+		}
+		if tt.download != "" {
+			data, err := os.ReadFile(tt.download)
+			if err != nil || string(data) != "select 1;" {
+				t.Fatalf("%s: unexpected download: %q, %v", tt.name, data, err)
+			}
+		}
+	}
+}
+
+func TestS3JobPolicy(t *testing.T) {
+	t.Setenv("ONEOFF_SECRET_TEST_S3_ACCESS_KEY_ID", "test")
+	t.Setenv("ONEOFF_SECRET_TEST_S3_SECRET_ACCESS_KEY", "test")
+	setTestSecretPolicy(t)
+
+	endpoint := startTestS3Server(t, "backups")
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("private"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret"), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	target := `"endpoint":"` + endpoint + `","insecure":true,"path_style":true,"bucket":"backups"`
+
+	tests := []struct {
+		name    string
+		config  string
+		policy  bool
+		message string // expected validation error, empty if the config is valid
+		failure string // expected execution error of a valid config
+	}{
+		{"put outside roots", `{` + target + `,` + testS3Credentials + `,"operation":"put","key":"k","source":"` + filepath.Join(outside, "secret") + `"}`, true, "outside the allowed roots", ""},
+		{"get outside roots", `{` + target + `,` + testS3Credentials + `,"operation":"get","key":"k","destination":"` + filepath.Join(outside, "out") + `"}`, true, "outside the allowed roots", ""},
+		{"delete without prefix", `{` + target + `,` + testS3Credentials + `,"operation":"delete"}`, false, "prefix is required", ""},
+		{"ambient credentials", `{` + target + `,"operation":"delete","prefix":"db/"}`, true, "S3_AMBIENT_CREDENTIALS", ""},
+		{"delete whole bucket", `{` + target + `,` + testS3Credentials + `,"operation":"delete","allow_empty_prefix":true}`, false, "", ""},
+		{"symlinked source", `{` + target + `,` + testS3Credentials + `,"operation":"put","key":"k","source":"` + filepath.Join(root, "link") + `"}`, false, "", "resolves outside the allowed roots"},
+	}
+	for _, tt := range tests {
+		job, err := NewS3Job(tt.config, S3Policy{AllowedRoots: []string{root}})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		err = job.Validate()
+		if tt.message != "" {
+			var violation *domain.PolicyViolation
+			if err == nil || !strings.Contains(err.Error(), tt.message) || errors.As(err, &violation) != tt.policy {
+				t.Errorf("%s: unexpected validation error %v", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected validation error %v", tt.name, err)
+			continue
+		}
+		if tt.failure == "" {
+			continue
+		}
+
+		// Symlinks are resolved when the job runs
+		result, err := job.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if result.ExitCode == 0 || !strings.Contains(result.Error, tt.failure) {
+			t.Errorf("%s: exit %d, error %q; want a failure containing %q", tt.name, result.ExitCode, result.Error, tt.failure)
+		}
+	}
+}
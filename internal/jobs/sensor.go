@@ -0,0 +1,242 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"github.com/meysam81/x/sqlite"
+)
+
+const (
+	sensorDefaultPokeInterval = 30
+	sensorDefaultTimeout      = 3600
+
+	// sensorPokeTimeout bounds a single check
+	sensorPokeTimeout = 30 * time.Second
+)
+
+// SensorJob implements JobExecutor for jobs that wait until a condition is met.
+// Each execution performs a single poke and asks to be rescheduled when the
+// condition is not met yet, so no worker is held while waiting.
+type SensorJob struct {
+	config       *domain.SensorJobConfig
+	allowedRoots []string
+}
+
+// NewSensorJob creates a new sensor job whose file and sqlite paths must lie within allowedRoots
+func NewSensorJob(config string, allowedRoots []string) (domain.JobExecutor, error) {
+	cfg, err := domain.ParseSensorJobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sensor job config: %w", err)
+	}
+
+	if cfg.PokeInterval == 0 {
+		cfg.PokeInterval = sensorDefaultPokeInterval
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = sensorDefaultTimeout
+	}
+	if cfg.Kind == "http" && cfg.ExpectedStatus == 0 {
+		cfg.ExpectedStatus = http.StatusOK
+	}
+
+	return &SensorJob{config: cfg, allowedRoots: allowedRoots}, nil
+}
+
+// Type returns the job type
+func (j *SensorJob) Type() string {
+	return "sensor"
+}
+
+// Description returns job description
+func (j *SensorJob) Description() string {
+	switch j.config.Kind {
+	case "file":
+		return fmt.Sprintf("Wait for file: %s", j.config.Path)
+	case "tcp":
+		return fmt.Sprintf("Wait for TCP port: %s", j.config.Address)
+	case "http":
+		return fmt.Sprintf("Wait for HTTP %d from %s", j.config.ExpectedStatus, j.config.URL)
+	case "sqlite":
+		return fmt.Sprintf("Wait for rows in %s", j.config.Path)
+	}
+	return "Wait for condition"
+}
+
+// Validate validates the job configuration
+func (j *SensorJob) Validate() error {
+	switch j.config.Kind {
+	case "file":
+		if j.config.Path == "" {
+			return fmt.Errorf("path is required for file sensors")
+		}
+		if _, err := filepath.Match(j.config.Path, ""); err != nil {
+			return fmt.Errorf("invalid path pattern: %w", err)
+		}
+		if err := checkAllowedPath("path", j.config.Path, j.allowedRoots); err != nil {
+			return err
+		}
+	case "tcp":
+		if _, _, err := net.SplitHostPort(j.config.Address); err != nil {
+			return fmt.Errorf("address must be host:port: %w", err)
+		}
+	case "http":
+		if j.config.URL == "" {
+			return fmt.Errorf("url is required for http sensors")
+		}
+	case "sqlite":
+		if j.config.Path == "" || j.config.Query == "" {
+			return fmt.Errorf("path and query are required for sqlite sensors")
+		}
+		if err := checkAllowedPath("path", j.config.Path, j.allowedRoots); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid sensor kind: %s (must be file, tcp, http or sqlite)", j.config.Kind)
+	}
+
+	if j.config.PokeInterval < 1 {
+		return fmt.Errorf("poke_interval must be at least 1 second")
+	}
+	if j.config.Timeout < 1 {
+		return fmt.Errorf("timeout must be at least 1 second")
+	}
+	return nil
+}
+
+// Execute pokes the condition once
+func (j *SensorJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	firstPoke, ok := domain.FirstAttemptFromContext(ctx)
+	if !ok {
+		firstPoke = time.Now()
+	}
+
+	pokeCtx, cancel := context.WithTimeout(ctx, sensorPokeTimeout)
+	met, detail := j.poke(pokeCtx)
+	cancel()
+
+	if ctx.Err() == context.Canceled {
+		return &domain.ExecutionResult{
+			ExitCode: 130,
+			Error:    "Job cancelled by user",
+		}, nil
+	}
+
+	line := fmt.Sprintf("[%s] %s\n", time.Now().UTC().Format(time.RFC3339), detail)
+	if met {
+		return &domain.ExecutionResult{Output: line + "Condition met\n"}, nil
+	}
+
+	waited := time.Since(firstPoke)
+	timeout := time.Duration(j.config.Timeout) * time.Second
+	if waited >= timeout {
+		if j.config.SoftFail {
+			return &domain.ExecutionResult{
+				Output: line + fmt.Sprintf("Condition not met within %ds, soft-failing\n", j.config.Timeout),
+			}, nil
+		}
+		return &domain.ExecutionResult{
+			Output:   line,
+			ExitCode: 124,
+			Error:    fmt.Sprintf("Condition not met within %ds", j.config.Timeout),
+		}, nil
+	}
+
+	// Never sleep past the deadline
+	next := time.Duration(j.config.PokeInterval) * time.Second
+	if remaining := timeout - waited; next > remaining {
+		next = remaining
+	}
+
+	return &domain.ExecutionResult{
+		Output:          line,
+		RescheduleAfter: next,
+	}, nil
+}
+
+// poke checks the condition once and describes the outcome
+func (j *SensorJob) poke(ctx context.Context) (bool, string) {
+	switch j.config.Kind {
+	case "file":
+		// Matches reached through a symlink leaving the allowed roots do not count
+		matches, _ := filepath.Glob(j.config.Path)
+		for _, match := range matches {
+			if _, err := resolveAllowedPath("path", match, j.allowedRoots); err == nil {
+				return true, fmt.Sprintf("Found %s", match)
+			}
+		}
+		return false, fmt.Sprintf("No file matches %s", j.config.Path)
+
+	case "tcp":
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", j.config.Address)
+		if err != nil {
+			return false, fmt.Sprintf("Cannot connect to %s: %v", j.config.Address, err)
+		}
+		_ = conn.Close()
+		return true, fmt.Sprintf("Connected to %s", j.config.Address)
+
+	case "http":
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.config.URL, nil)
+		if err != nil {
+			return false, fmt.Sprintf("Invalid URL: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false, fmt.Sprintf("Request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != j.config.ExpectedStatus {
+			return false, fmt.Sprintf("Got status %d, waiting for %d", resp.StatusCode, j.config.ExpectedStatus)
+		}
+		return true, fmt.Sprintf("Got status %d", resp.StatusCode)
+
+	case "sqlite":
+		path, err := resolveAllowedPath("path", j.config.Path, j.allowedRoots)
+		if err != nil {
+			return false, err.Error()
+		}
+		// Opening the file directly would create it, so a missing database is just "not yet"
+		if _, err := os.Stat(path); err != nil {
+			return false, fmt.Sprintf("Database %s not found", j.config.Path)
+		}
+		dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}).String()
+		db, err := sql.Open(sqlite.ENGINE, dsn)
+		if err != nil {
+			return false, fmt.Sprintf("Cannot open database: %v", err)
+		}
+		defer func() { _ = db.Close() }()
+
+		// A single connection, so that query_only applies to the query
+		db.SetMaxOpenConns(1)
+		if _, err := db.ExecContext(ctx, "PRAGMA query_only = 1"); err != nil {
+			return false, fmt.Sprintf("Cannot open database: %v", err)
+		}
+		rows, err := db.QueryContext(ctx, j.config.Query)
+		if err != nil {
+			return false, fmt.Sprintf("Query failed: %v", err)
+		}
+		defer func() { _ = rows.Close() }()
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return false, fmt.Sprintf("Query failed: %v", err)
+			}
+			return false, "Query returned no rows"
+		}
+		return true, "Query returned rows"
+	}
+
+	return false, fmt.Sprintf("Unknown sensor kind: %s", j.config.Kind)
+}
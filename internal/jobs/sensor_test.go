@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"github.com/meysam81/x/sqlite"
+)
+
+func TestSensorPaths(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "ready"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "ready"), filepath.Join(root, "link.done")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory name that would add URI parameters if the path were pasted into the DSN
+	plainDir := filepath.Join(root, "db")
+	if err := os.Mkdir(plainDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(sqlite.ENGINE, filepath.Join(plainDir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE jobs (id INTEGER); INSERT INTO jobs VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	_ = db.Close()
+	dbDir := filepath.Join(root, "db?mode=rwc&cache=shared")
+	if err := os.Rename(plainDir, dbDir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		policy string // expected policy violation
+		met    bool
+		detail string
+	}{
+		{"file outside roots", `{"kind":"file","path":"` + outside + `/*"}`, "path: path is outside the allowed roots", false, ""},
+		{"sqlite outside roots", `{"kind":"sqlite","path":"` + outside + `/oneoff.db","query":"SELECT 1"}`, "path: path is outside the allowed roots", false, ""},
+		{"symlinked match", `{"kind":"file","path":"` + root + `/*.done"}`, "", false, "No file matches"},
+		{"sqlite rows", `{"kind":"sqlite","path":"` + filepath.Join(dbDir, "app.db") + `","query":"SELECT id FROM jobs"}`, "", true, "Query returned rows"},
+		{"sqlite write", `{"kind":"sqlite","path":"` + filepath.Join(dbDir, "app.db") + `","query":"DELETE FROM jobs RETURNING id"}`, "", false, "Query failed"},
+		{"sqlite missing", `{"kind":"sqlite","path":"` + filepath.Join(dbDir, "missing.db") + `","query":"SELECT 1"}`, "", false, "not found"},
+	}
+	for _, tt := range tests {
+		job, err := NewSensorJob(tt.config, []string{root})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = job.Validate()
+		if tt.policy != "" {
+			var violation *domain.PolicyViolation
+			if err == nil || !strings.Contains(err.Error(), tt.policy) || !errors.As(err, &violation) {
+				t.Errorf("%s: expected a policy violation containing %q, got %v", tt.name, tt.policy, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		met, detail := job.(*SensorJob).poke(context.Background())
+		if met != tt.met || !strings.Contains(detail, tt.detail) {
+			t.Errorf("%s: poke = %v, %q; want %v, %q", tt.name, met, detail, tt.met, tt.detail)
+		}
+	}
+
+	if err := os.Rename(dbDir, plainDir); err != nil {
+		t.Fatal(err)
+	}
+	db, err = sql.Open(sqlite.ENGINE, filepath.Join(plainDir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+	var count int
+	if err := db.QueryRow("SELECT count(*) FROM jobs").Scan(&count); err != nil || count != 1 {
+		t.Fatalf("the sensor modified the database: %d rows, %v", count, err)
+	}
+}
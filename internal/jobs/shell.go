@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,16 +17,18 @@ import (
 // ShellJob implements JobExecutor for shell scripts
 type ShellJob struct {
 	config *domain.ShellJobConfig
+	policy ShellPolicy
+	run    *domain.RunContext
 }
 
-// NewShellJob creates a new shell job
-func NewShellJob(config string) (domain.JobExecutor, error) {
+// NewShellJob creates a new shell job restricted by the given policy
+func NewShellJob(config string, policy ShellPolicy) (domain.JobExecutor, error) {
 	cfg, err := domain.ParseShellJobConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("invalid shell job config: %w", err)
 	}
 
-	return &ShellJob{config: cfg}, nil
+	return &ShellJob{config: cfg, policy: policy}, nil
 }
 
 // Type returns the job type
@@ -35,6 +38,9 @@ func (j *ShellJob) Type() string {
 
 // Description returns job description
 func (j *ShellJob) Description() string {
+	if len(j.config.Steps) > 0 {
+		return fmt.Sprintf("Execute %d shell steps", len(j.config.Steps))
+	}
 	if j.config.IsPath {
 		return fmt.Sprintf("Execute shell script: %s", j.config.Script)
 	}
@@ -45,8 +51,37 @@ func (j *ShellJob) Description() string {
 	return fmt.Sprintf("Execute shell command: %s", scriptPreview)
 }
 
+// SetRunContext exposes the execution's identity to the script as ONEOFF_* variables
+func (j *ShellJob) SetRunContext(rc *domain.RunContext) {
+	j.run = rc
+}
+
 // Validate validates the job configuration
 func (j *ShellJob) Validate() error {
+	if err := j.validateInterpreter(); err != nil {
+		return err
+	}
+	if err := j.validateSandbox(); err != nil {
+		return err
+	}
+	for key := range j.config.Env {
+		if !domain.IsValidEnvName(key) {
+			return fmt.Errorf("invalid environment variable name: %q", key)
+		}
+	}
+	if len(j.config.Steps) > 0 {
+		if j.config.Script != "" || j.config.IsPath {
+			return fmt.Errorf("script and steps are mutually exclusive")
+		}
+		names := make([]string, len(j.config.Steps))
+		for i, step := range j.config.Steps {
+			if step.Script == "" {
+				return fmt.Errorf("steps[%d].script is required", i)
+			}
+			names[i] = step.Name
+		}
+		return validateStepNames(names)
+	}
 	if j.config.Script == "" {
 		return fmt.Errorf("script is required")
 	}
@@ -79,24 +114,33 @@ func (j *ShellJob) Execute(ctx context.Context) (*domain.ExecutionResult, error)
 		defer cancel()
 	}
 
-	var cmd *exec.Cmd
+	if len(j.config.Steps) > 0 {
+		return j.executeSteps(ctx)
+	}
 
-	if j.config.IsPath {
-		// Execute script file
-		cmd = exec.CommandContext(ctx, "/bin/sh", j.config.Script)
-		if len(j.config.Args) > 0 {
-			cmd.Args = append(cmd.Args, j.config.Args...)
-		}
-	} else {
-		// Execute inline script
-		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", j.config.Script)
-		if len(j.config.Args) > 0 {
-			// For inline scripts, args need to be passed differently
-			// We'll pass them as positional parameters
-			script := j.config.Script + " " + strings.Join(j.config.Args, " ")
-			cmd = exec.CommandContext(ctx, "/bin/sh", "-c", script)
-		}
+	sb, err := j.newSandbox()
+	if err != nil {
+		return &domain.ExecutionResult{
+			ExitCode: 1,
+			Error:    fmt.Sprintf("Failed to prepare sandbox: %v", err),
+		}, nil
+	}
+	defer sb.close()
+
+	outputs, err := newOutputsDir(sb)
+	if err != nil {
+		return nil, err
+	}
+	defer outputs.close()
+
+	cmd, cleanup, err := j.command(ctx, j.config.Script, j.config.IsPath, j.config.Args, sb)
+	if err != nil {
+		return &domain.ExecutionResult{
+			ExitCode: 127,
+			Error:    fmt.Sprintf("Failed to prepare script: %v", err),
+		}, nil
 	}
+	defer cleanup()
 
 	// Set working directory
 	if j.config.WorkDir != "" {
@@ -104,14 +148,10 @@ func (j *ShellJob) Execute(ctx context.Context) (*domain.ExecutionResult, error)
 	}
 
 	// Set environment variables
-	if len(j.config.Env) > 0 {
-		cmd.Env = os.Environ()
-		for key, value := range j.config.Env {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
-		}
-	}
+	var envKeys []string
+	cmd.Env, envKeys = j.environ(ctx, sb.home(), outputs.file())
 
-	setSysProcAttr(cmd)
+	setSysProcAttr(cmd, sb)
 
 	// Capture output
 	var stdout, stderr bytes.Buffer
@@ -119,7 +159,7 @@ func (j *ShellJob) Execute(ctx context.Context) (*domain.ExecutionResult, error)
 	cmd.Stderr = &stderr
 
 	// Execute command
-	err := cmd.Run()
+	err = sb.run(ctx, cmd)
 
 	exitCode := 0
 	errorMsg := ""
@@ -129,6 +169,9 @@ func (j *ShellJob) Execute(ctx context.Context) (*domain.ExecutionResult, error)
 			killProcessGroup(cmd)
 			exitCode = 130 // SIGINT exit code
 			errorMsg = "Job cancelled by user"
+		} else if limitErr, ok := err.(*resourceLimitError); ok {
+			exitCode = limitErr.exitCode
+			errorMsg = fmt.Sprintf("Resource limit exceeded: %s", limitErr.reason)
 		} else if exitErr, ok := err.(*exec.ExitError); ok {
 			exitCode = exitErr.ExitCode()
 			errorMsg = fmt.Sprintf("Script exited with code %d: %s", exitCode, stderr.String())
@@ -150,9 +193,188 @@ func (j *ShellJob) Execute(ctx context.Context) (*domain.ExecutionResult, error)
 		output += stderr.String()
 	}
 
-	return &domain.ExecutionResult{
+	result := &domain.ExecutionResult{
 		Output:   output,
 		ExitCode: exitCode,
 		Error:    errorMsg,
-	}, nil
+		EnvKeys:  envKeys,
+		Usage:    processUsage(cmd.ProcessState),
+		Outputs:  map[string]string{},
+	}
+	parseSetOutputs(stdout.String(), result.Outputs)
+	outputs.collect(result)
+	return result, nil
+}
+
+// executeSteps runs the configured steps in a shared working directory
+func (j *ShellJob) executeSteps(ctx context.Context) (*domain.ExecutionResult, error) {
+	sb, err := j.newSandbox()
+	if err != nil {
+		return &domain.ExecutionResult{
+			ExitCode: 1,
+			Error:    fmt.Sprintf("Failed to prepare sandbox: %v", err),
+		}, nil
+	}
+	defer sb.close()
+
+	outputs, err := newOutputsDir(sb)
+	if err != nil {
+		return nil, err
+	}
+	defer outputs.close()
+
+	workDir := j.config.WorkDir
+	if workDir == "" {
+		tmp, err := os.MkdirTemp("", "oneoff-steps-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(tmp) }()
+		if err := sb.chown(tmp); err != nil {
+			return nil, fmt.Errorf("failed to hand over working directory: %w", err)
+		}
+		workDir = tmp
+	}
+	env, envKeys := j.environ(ctx, sb.home(), outputs.file())
+
+	steps := make([]jobStep, len(j.config.Steps))
+	for i, step := range j.config.Steps {
+		script := step.Script
+		steps[i] = jobStep{
+			name:            step.Name,
+			continueOnError: step.ContinueOnError,
+			timeout:         step.Timeout,
+			run: func(ctx context.Context) (string, string, *domain.ResourceUsage, error) {
+				cmd, cleanup, err := j.command(ctx, script, false, nil, sb)
+				if err != nil {
+					return "", "", nil, err
+				}
+				defer cleanup()
+
+				cmd.Dir = workDir
+				cmd.Env = env
+				setSysProcAttr(cmd, sb)
+				// Kill the whole process group so children holding the output pipes do not outlive the step
+				cmd.Cancel = func() error {
+					killProcessGroup(cmd)
+					return nil
+				}
+
+				var stdout, stderr bytes.Buffer
+				cmd.Stdout = &stdout
+				cmd.Stderr = &stderr
+
+				err = sb.run(ctx, cmd)
+				return stdout.String(), stderr.String(), processUsage(cmd.ProcessState), err
+			},
+		}
+	}
+
+	result := runSteps(ctx, steps, "Script execution timeout")
+	result.EnvKeys = envKeys
+	outputs.collect(result)
+	return result, nil
+}
+
+// DryRun checks the syntax of the script, or of every step, with the interpreter's -n flag
+func (j *ShellJob) DryRun(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	name, _ := j.interpreter()
+	if base := filepath.Base(name); base != "sh" && base != "bash" {
+		return &domain.ExecutionResult{
+			Output: fmt.Sprintf("Syntax check is not available for interpreter %s\n", name),
+		}, nil
+	}
+	bin, _, err := j.interpreterPath()
+	if err != nil {
+		return &domain.ExecutionResult{
+			ExitCode: 127,
+			Error:    err.Error(),
+		}, nil
+	}
+
+	type script struct {
+		name   string
+		script string
+		isPath bool
+	}
+	scripts := []script{{name: "script", script: j.config.Script, isPath: j.config.IsPath}}
+	if len(j.config.Steps) > 0 {
+		scripts = scripts[:0]
+		for _, step := range j.config.Steps {
+			scripts = append(scripts, script{name: "step " + step.Name, script: step.Script})
+		}
+	}
+
+	var output strings.Builder
+	for _, s := range scripts {
+		// Inline scripts are read from stdin, so that errors are not reported against a temporary file
+		cmd := exec.CommandContext(ctx, bin, "-n")
+		if s.isPath {
+			cmd.Args = append(cmd.Args, s.script)
+		} else {
+			cmd.Stdin = strings.NewReader(s.script)
+		}
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			output.WriteString(fmt.Sprintf("%s: syntax error\n%s", s.name, out))
+			exitCode := 1
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				exitCode = exitErr.ExitCode()
+			}
+			return &domain.ExecutionResult{
+				Output:   output.String(),
+				ExitCode: exitCode,
+				Error:    fmt.Sprintf("Syntax check of %s failed", s.name),
+			}, nil
+		}
+		output.WriteString(fmt.Sprintf("%s: syntax OK\n", s.name))
+	}
+
+	return &domain.ExecutionResult{Output: output.String()}, nil
+}
+
+// environ builds the job's environment from a minimal base, the admin-allowed inherited variables,
+// the run context, the project's variables and the job's own variables, in increasing precedence. The path of
+// the outputs file is always set. It also returns the sorted variable names.
+func (j *ShellJob) environ(ctx context.Context, home, outputsFile string) ([]string, []string) {
+	vars := map[string]string{
+		"PATH": shellDefaultPath,
+		"HOME": home,
+	}
+
+	for _, entry := range os.Environ() {
+		key, value, ok := strings.Cut(entry, "=")
+		if ok && j.policy.inherits(key) {
+			vars[key] = value
+		}
+	}
+	if j.run != nil {
+		for key, value := range j.run.Env() {
+			vars[key] = value
+		}
+	}
+	for key, value := range domain.ProjectEnvFromContext(ctx) {
+		vars[key] = value
+	}
+	for key, value := range j.config.Env {
+		vars[key] = value
+	}
+	vars[outputsEnvVar] = outputsFile
+
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+	return env, keys
 }
@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// builtinInterpreters maps the interpreter names jobs may use without a path to their commands
+var builtinInterpreters = map[string]string{
+	"sh":      "/bin/sh",
+	"bash":    "bash",
+	"python3": "python3",
+	"node":    "node",
+}
+
+// interpreter returns the configured interpreter split into its command and arguments
+func (j *ShellJob) interpreter() (string, []string) {
+	fields := strings.Fields(j.config.Interpreter)
+	if len(fields) == 0 {
+		return "sh", nil
+	}
+	return fields[0], fields[1:]
+}
+
+// validateInterpreter checks the interpreter against the admin allowlist
+func (j *ShellJob) validateInterpreter() error {
+	name, _ := j.interpreter()
+
+	if _, ok := builtinInterpreters[name]; !ok && !filepath.IsAbs(name) {
+		return fmt.Errorf("interpreter must be sh, bash, python3, node or an absolute path: %s", name)
+	}
+
+	allowed := j.policy.AllowedInterpreters
+	if len(allowed) == 0 {
+		allowed = []string{"sh"}
+	}
+	if !slices.Contains(allowed, name) {
+		return domain.NewPolicyViolation("interpreter is not allowed: %s (allowed: %s)", name, strings.Join(allowed, ", "))
+	}
+
+	return nil
+}
+
+// interpreterPath returns the absolute path of the configured interpreter and its arguments
+func (j *ShellJob) interpreterPath() (string, []string, error) {
+	name, interpreterArgs := j.interpreter()
+
+	bin := name
+	if builtin, ok := builtinInterpreters[name]; ok {
+		bin = builtin
+	}
+	bin, err := exec.LookPath(bin)
+	if err != nil {
+		return "", nil, fmt.Errorf("interpreter not found: %w", err)
+	}
+	return bin, interpreterArgs, nil
+}
+
+// command builds the command running a script file, or an inline script written to a temporary file.
+// The returned cleanup function removes the temporary file once the command has finished.
+func (j *ShellJob) command(ctx context.Context, script string, isPath bool, args []string, sb *shellSandbox) (*exec.Cmd, func(), error) {
+	bin, interpreterArgs, err := j.interpreterPath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {}
+	scriptPath := script
+	if !isPath {
+		scriptPath, err = writeScriptFile(script, "#!"+strings.Join(append([]string{bin}, interpreterArgs...), " "))
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup = func() { _ = os.Remove(scriptPath) }
+		if err := sb.chown(scriptPath); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to hand over script file: %w", err)
+		}
+	}
+
+	// Arguments are passed as real positional parameters after the script
+	cmdArgs := append(append(slices.Clone(interpreterArgs), scriptPath), args...)
+	return exec.CommandContext(ctx, bin, cmdArgs...), cleanup, nil
+}
+
+// writeScriptFile writes an inline script to a private temporary file, adding the shebang if missing
+func writeScriptFile(script, shebang string) (string, error) {
+	file, err := os.CreateTemp("", "oneoff-script-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create script file: %w", err)
+	}
+
+	content := script
+	if !strings.HasPrefix(script, "#!") {
+		content = shebang + "\n" + script
+	}
+
+	if _, err := file.WriteString(content); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write script file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write script file: %w", err)
+	}
+	if err := os.Chmod(file.Name(), 0o700); err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to make script executable: %w", err)
+	}
+
+	return file.Name(), nil
+}
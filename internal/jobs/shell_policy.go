@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"os"
+	"path"
+)
+
+// shellDefaultPath is the PATH shell jobs start with unless PATH is inherited or set
+const shellDefaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// ShellPolicy holds the admin-level restrictions applied to shell jobs
+type ShellPolicy struct {
+	// AllowedInterpreters lists interpreter names or absolute paths jobs may use; empty allows only sh
+	AllowedInterpreters []string
+	// InheritEnv lists server environment variables passed to jobs; entries may be globs such as LC_*
+	InheritEnv []string
+	// AllowedRunAs lists the users, as names or uid[:gid], jobs may run as; empty disables run_as
+	AllowedRunAs []string
+	// CgroupParent is a delegated cgroup v2 directory under which jobs with memory, CPU or process caps get a sub-group
+	CgroupParent string
+}
+
+// inherits reports whether the server environment variable may be passed to jobs
+func (p ShellPolicy) inherits(key string) bool {
+	for _, pattern := range p.InheritEnv {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsRunAs reports whether jobs may run as the resolved user and group
+func (p ShellPolicy) allowsRunAs(credential *shellCredential) bool {
+	for _, spec := range p.AllowedRunAs {
+		allowed, err := resolveRunAs(spec)
+		if err == nil && allowed.uid == credential.uid && allowed.gid == credential.gid {
+			return true
+		}
+	}
+	return false
+}
+
+// shellHome returns the home directory given to shell jobs
+func shellHome() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return "/"
+}
@@ -0,0 +1,198 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// shellSandbox holds the identity, resource limits and cgroup applied to a shell job's processes
+type shellSandbox struct {
+	credential *shellCredential // nil keeps the server's user
+	limits     *domain.ShellLimits
+	cgroup     *shellCgroup // nil when no cgroup caps are configured
+}
+
+// shellCredential is the user a shell job runs as
+type shellCredential struct {
+	uid    uint32
+	gid    uint32
+	groups []uint32
+	home   string
+}
+
+// resourceLimitError reports a process killed for exceeding a resource limit
+type resourceLimitError struct {
+	reason   string
+	exitCode int
+}
+
+func (e *resourceLimitError) Error() string {
+	return "resource limit exceeded: " + e.reason
+}
+
+// validateSandbox checks run_as and limits against the platform and the policy
+func (j *ShellJob) validateSandbox() error {
+	if j.config.RunAs != "" {
+		if runtime.GOOS == "windows" {
+			return fmt.Errorf("run_as is not supported on Windows")
+		}
+		credential, err := resolveRunAs(j.config.RunAs)
+		if err != nil {
+			return err
+		}
+		if len(j.policy.AllowedRunAs) == 0 {
+			return domain.NewPolicyViolation("run_as is disabled on this server (SHELL_ALLOWED_RUN_AS)")
+		}
+		if !j.policy.allowsRunAs(credential) {
+			return domain.NewPolicyViolation("run_as is not allowed: %s (allowed: %s)", j.config.RunAs, strings.Join(j.policy.AllowedRunAs, ", "))
+		}
+	}
+
+	limits := j.config.Limits
+	if limits == nil {
+		return nil
+	}
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("limits are only supported on Linux")
+	}
+	if limits.CPUs < 0 {
+		return fmt.Errorf("limits.cpus cannot be negative")
+	}
+	if (limits.MemoryMB > 0 || limits.CPUs > 0) && j.policy.CgroupParent == "" {
+		return domain.NewPolicyViolation("limits.memory_mb and limits.cpus require a cgroup parent (SHELL_CGROUP_PARENT)")
+	}
+	return nil
+}
+
+// newSandbox resolves the run_as user and creates the job's cgroup if needed
+func (j *ShellJob) newSandbox() (*shellSandbox, error) {
+	sb := &shellSandbox{limits: j.config.Limits}
+
+	if j.config.RunAs != "" {
+		credential, err := resolveRunAs(j.config.RunAs)
+		if err != nil {
+			return nil, err
+		}
+		sb.credential = credential
+	}
+
+	if j.policy.CgroupParent != "" && sb.limits != nil && (sb.limits.MemoryMB > 0 || sb.limits.CPUs > 0 || sb.limits.Processes > 0) {
+		cgroup, err := newShellCgroup(j.policy.CgroupParent, sb.limits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cgroup: %w", err)
+		}
+		sb.cgroup = cgroup
+	}
+
+	return sb, nil
+}
+
+// close removes the job's cgroup, killing anything still running in it
+func (sb *shellSandbox) close() {
+	if sb.cgroup != nil {
+		sb.cgroup.close()
+	}
+}
+
+// home returns the home directory of the user the job runs as
+func (sb *shellSandbox) home() string {
+	if sb.credential != nil && sb.credential.home != "" {
+		return sb.credential.home
+	}
+	return shellHome()
+}
+
+// chown gives the run_as user ownership of a file the server created for the job
+func (sb *shellSandbox) chown(path string) error {
+	if sb.credential == nil {
+		return nil
+	}
+	return os.Chown(path, int(sb.credential.uid), int(sb.credential.gid))
+}
+
+// run starts the command with the resource limits applied and waits for it. A process killed
+// for exceeding a limit is reported as a *resourceLimitError.
+func (sb *shellSandbox) run(ctx context.Context, cmd *exec.Cmd) error {
+	oomBefore := sb.cgroup.oomKills()
+
+	if err := applyRlimits(cmd, sb.limits, sb.cgroup != nil); err != nil {
+		return fmt.Errorf("failed to apply resource limits: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	err := cmd.Wait()
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	if sb.cgroup.oomKills() > oomBefore {
+		return &resourceLimitError{
+			reason:   fmt.Sprintf("memory limit of %d MB (killed by the OOM killer)", sb.limits.MemoryMB),
+			exitCode: 137,
+		}
+	}
+	if limitErr := rlimitKill(cmd.ProcessState, sb.limits); limitErr != nil {
+		return limitErr
+	}
+	return err
+}
+
+// resolveRunAs looks up a user name or a numeric uid[:gid]
+func resolveRunAs(spec string) (*shellCredential, error) {
+	name, group, hasGroup := strings.Cut(spec, ":")
+
+	var u *user.User
+	var err error
+	if _, convErr := strconv.ParseUint(name, 10, 32); convErr == nil {
+		u, err = user.LookupId(name)
+		if err != nil {
+			// Numeric ids do not need a passwd entry
+			u = &user.User{Uid: name, Gid: name}
+		}
+	} else {
+		u, err = user.Lookup(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run_as: %w", err)
+		}
+	}
+
+	uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+	gidStr := u.Gid
+	if hasGroup {
+		gidStr = group
+		if _, convErr := strconv.ParseUint(group, 10, 32); convErr != nil {
+			g, err := user.LookupGroup(group)
+			if err != nil {
+				return nil, fmt.Errorf("invalid run_as group: %w", err)
+			}
+			gidStr = g.Gid
+		}
+	}
+	gid, err := strconv.ParseUint(gidStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid run_as group: %s", gidStr)
+	}
+
+	credential := &shellCredential{uid: uint32(uid), gid: uint32(gid), home: u.HomeDir}
+	if !hasGroup {
+		// Supplementary groups are only kept when the primary group was not overridden
+		if ids, err := u.GroupIds(); err == nil {
+			for _, id := range ids {
+				if n, err := strconv.ParseUint(id, 10, 32); err == nil {
+					credential.groups = append(credential.groups, uint32(n))
+				}
+			}
+		}
+	}
+	return credential, nil
+}
@@ -0,0 +1,223 @@
+//go:build linux
+
+package jobs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"golang.org/x/sys/unix"
+)
+
+// cgroupCPUPeriod is the cpu.max period in microseconds
+const cgroupCPUPeriod = 100000
+
+// shellCgroup is a cgroup v2 sub-group holding the processes of one execution
+type shellCgroup struct {
+	dir string
+	fd  int
+}
+
+// newShellCgroup creates a sub-group of parent with the configured caps
+func newShellCgroup(parent string, limits *domain.ShellLimits) (*shellCgroup, error) {
+	// Best effort: the parent may already delegate these controllers
+	_ = os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory +cpu +pids"), 0)
+
+	dir, err := os.MkdirTemp(parent, "oneoff-job-")
+	if err != nil {
+		return nil, err
+	}
+	cg := &shellCgroup{dir: dir, fd: -1}
+
+	settings := map[string]string{}
+	if limits.MemoryMB > 0 {
+		settings["memory.max"] = strconv.FormatUint(limits.MemoryMB*1024*1024, 10)
+	}
+	if limits.CPUs > 0 {
+		quota := max(int64(limits.CPUs*cgroupCPUPeriod), 1000)
+		settings["cpu.max"] = fmt.Sprintf("%d %d", quota, cgroupCPUPeriod)
+	}
+	if limits.Processes > 0 {
+		settings["pids.max"] = strconv.FormatUint(limits.Processes, 10)
+	}
+	for file, value := range settings {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0); err != nil {
+			cg.close()
+			return nil, fmt.Errorf("failed to set %s (is the controller enabled in %s/cgroup.subtree_control?): %w", file, parent, err)
+		}
+	}
+	if limits.MemoryMB > 0 {
+		// Keep the cap from being bypassed through swap; absent without swap accounting
+		_ = os.WriteFile(filepath.Join(dir, "memory.swap.max"), []byte("0"), 0)
+	}
+
+	fd, err := unix.Open(dir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		cg.close()
+		return nil, err
+	}
+	cg.fd = fd
+
+	return cg, nil
+}
+
+// oomKills returns the number of processes the OOM killer has killed in the cgroup
+func (c *shellCgroup) oomKills() uint64 {
+	if c == nil {
+		return 0
+	}
+	file, err := os.Open(filepath.Join(c.dir, "memory.events"))
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "oom_kill "); ok {
+			n, _ := strconv.ParseUint(value, 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// close kills any leftover processes and removes the cgroup
+func (c *shellCgroup) close() {
+	if c.fd >= 0 {
+		_ = unix.Close(c.fd)
+	}
+	_ = os.WriteFile(filepath.Join(c.dir, "cgroup.kill"), []byte("1"), 0)
+
+	// Removal fails until the killed processes have been reaped
+	for range 20 {
+		if err := os.Remove(c.dir); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// applyCgroup places the child into the cgroup when it is created
+func applyCgroup(attr *syscall.SysProcAttr, cg *shellCgroup) {
+	if cg == nil {
+		return
+	}
+	attr.UseCgroupFD = true
+	attr.CgroupFD = cg.fd
+}
+
+// rlimitHelperArg marks a re-exec of the server binary that sets the resource limits of a job on
+// itself and then replaces itself with the job's command, so that the limits are in place before
+// the command runs. The re-exec happens after the switch to the run_as user and into the cgroup.
+const rlimitHelperArg = "oneoff-rlimit-exec"
+
+// rlimitResources maps the names used on the helper's command line to rlimit resources
+var rlimitResources = map[string]int{
+	"cpu":    unix.RLIMIT_CPU,
+	"as":     unix.RLIMIT_AS,
+	"nofile": unix.RLIMIT_NOFILE,
+	"nproc":  unix.RLIMIT_NPROC,
+}
+
+func init() {
+	// os.Args: server binary, rlimitHelperArg, limits, command path, command args...
+	if len(os.Args) > 4 && os.Args[1] == rlimitHelperArg {
+		runRlimitHelper(os.Args[2], os.Args[3], os.Args[4:])
+	}
+}
+
+// applyRlimits makes cmd start through the rlimit helper when the job has rlimits
+func applyRlimits(cmd *exec.Cmd, limits *domain.ShellLimits, inCgroup bool) error {
+	if limits == nil || cmd.Err != nil {
+		return nil
+	}
+
+	var spec []string
+	add := func(name string, value uint64, hard uint64) {
+		if value > 0 {
+			spec = append(spec, fmt.Sprintf("%s=%d:%d", name, value, hard))
+		}
+	}
+
+	// The hard CPU limit is one second later so the process gets SIGXCPU first
+	add("cpu", limits.CPUSeconds, limits.CPUSeconds+1)
+	add("as", limits.AddressSpaceMB*1024*1024, limits.AddressSpaceMB*1024*1024)
+	add("nofile", limits.OpenFiles, limits.OpenFiles)
+	// pids.max covers processes when a cgroup is used; RLIMIT_NPROC counts every process of the user
+	if !inCgroup {
+		add("nproc", limits.Processes, limits.Processes)
+	}
+	if len(spec) == 0 {
+		return nil
+	}
+
+	cmd.Args = append([]string{"oneoff", rlimitHelperArg, strings.Join(spec, ","), cmd.Path}, cmd.Args...)
+	cmd.Path = "/proc/self/exe"
+	return nil
+}
+
+// runRlimitHelper sets the limits in spec on the current process and executes path
+func runRlimitHelper(spec, path string, argv []string) {
+	fail := func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, "oneoff: "+format+"\n", args...)
+		os.Exit(126)
+	}
+
+	type rlimit struct {
+		name     string
+		resource int
+		limit    syscall.Rlimit
+	}
+	var limits []rlimit
+	for entry := range strings.SplitSeq(spec, ",") {
+		name, values, _ := strings.Cut(entry, "=")
+		cur, hard, _ := strings.Cut(values, ":")
+		resource, ok := rlimitResources[name]
+		curValue, curErr := strconv.ParseUint(cur, 10, 64)
+		hardValue, hardErr := strconv.ParseUint(hard, 10, 64)
+		if !ok || curErr != nil || hardErr != nil {
+			fail("invalid resource limit %q", entry)
+		}
+		limits = append(limits, rlimit{name: name, resource: resource, limit: syscall.Rlimit{Cur: curValue, Max: hardValue}})
+	}
+
+	// syscall.Setrlimit, unlike unix.Setrlimit, keeps the Go runtime from restoring its own
+	// open files limit on exec
+	for _, l := range limits {
+		if err := syscall.Setrlimit(l.resource, &l.limit); err != nil {
+			fail("failed to set %s limit: %v", l.name, err)
+		}
+	}
+
+	err := syscall.Exec(path, argv, os.Environ())
+	fail("failed to execute %s: %v", path, err)
+}
+
+// rlimitKill reports whether the process was killed for exceeding its CPU time limit
+func rlimitKill(state *os.ProcessState, limits *domain.ShellLimits) *resourceLimitError {
+	if state == nil || limits == nil || limits.CPUSeconds == 0 {
+		return nil
+	}
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return nil
+	}
+
+	cpuTime := state.UserTime() + state.SystemTime()
+	if status.Signal() == syscall.SIGXCPU || (status.Signal() == syscall.SIGKILL && cpuTime >= time.Duration(limits.CPUSeconds)*time.Second) {
+		return &resourceLimitError{
+			reason:   fmt.Sprintf("CPU time limit of %ds", limits.CPUSeconds),
+			exitCode: 128 + int(status.Signal()),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+//go:build linux
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+func TestShellRlimitsAppliedBeforeExec(t *testing.T) {
+	job, err := NewShellJob(`{"script":"ulimit -n; ulimit -t","limits":{"open_files":64,"cpu_seconds":30}}`, ShellPolicy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := job.Execute(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 0 || !strings.HasPrefix(result.Output, "64\n30\n") {
+		t.Fatalf("expected the limits to be in place when the script starts, got exit %d, output %q, error %q", result.ExitCode, result.Output, result.Error)
+	}
+}
+
+func TestShellRunAsPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		runAs   string
+		allowed []string
+		message string
+	}{
+		{"disabled", "0", nil, "run_as is disabled"},
+		{"not listed", "0", []string{"65534"}, "run_as is not allowed: 0"},
+		{"group override", "65534:0", []string{"65534"}, "run_as is not allowed: 65534:0"},
+		{"listed", "65534:65534", []string{"65534"}, ""},
+	}
+	for _, tt := range tests {
+		job, err := NewShellJob(`{"script":"true","run_as":"`+tt.runAs+`"}`, ShellPolicy{AllowedRunAs: tt.allowed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = job.Validate()
+		if tt.message == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		var violation *domain.PolicyViolation
+		if err == nil || !strings.Contains(err.Error(), tt.message) || !errors.As(err, &violation) {
+			t.Errorf("%s: expected a policy violation containing %q, got %v", tt.name, tt.message, err)
+		}
+	}
+}
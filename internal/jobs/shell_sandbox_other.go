@@ -0,0 +1,38 @@
+//go:build !linux
+
+package jobs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// shellCgroup is unavailable outside Linux
+type shellCgroup struct{}
+
+func newShellCgroup(string, *domain.ShellLimits) (*shellCgroup, error) {
+	return nil, fmt.Errorf("cgroups are only supported on Linux")
+}
+
+func (c *shellCgroup) oomKills() uint64 {
+	return 0
+}
+
+func (c *shellCgroup) close() {}
+
+func applyCgroup(*syscall.SysProcAttr, *shellCgroup) {}
+
+func applyRlimits(_ *exec.Cmd, limits *domain.ShellLimits, _ bool) error {
+	if limits != nil {
+		return fmt.Errorf("resource limits are only supported on Linux")
+	}
+	return nil
+}
+
+func rlimitKill(*os.ProcessState, *domain.ShellLimits) *resourceLimitError {
+	return nil
+}
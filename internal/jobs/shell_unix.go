@@ -7,8 +7,15 @@ import (
 	"syscall"
 )
 
-func setSysProcAttr(cmd *exec.Cmd) {
+func setSysProcAttr(cmd *exec.Cmd, sb *shellSandbox) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if sb == nil {
+		return
+	}
+	if c := sb.credential; c != nil {
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: c.uid, Gid: c.gid, Groups: c.groups}
+	}
+	applyCgroup(cmd.SysProcAttr, sb.cgroup)
 }
 
 func killProcessGroup(cmd *exec.Cmd) {
@@ -8,7 +8,7 @@ import (
 	"github.com/meysam81/oneoff/internal/logging"
 )
 
-func setSysProcAttr(cmd *exec.Cmd) {
+func setSysProcAttr(cmd *exec.Cmd, sb *shellSandbox) {
 }
 
 func killProcessGroup(cmd *exec.Cmd) {
@@ -0,0 +1,354 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHJob implements JobExecutor for remote commands over SSH
+type SSHJob struct {
+	config       *domain.SSHJobConfig
+	allowedRoots []string
+}
+
+// NewSSHJob creates a new SSH job whose known_hosts file must lie in the allowed roots
+func NewSSHJob(config string, allowedRoots []string) (domain.JobExecutor, error) {
+	cfg, err := domain.ParseSSHJobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH job config: %w", err)
+	}
+
+	if cfg.Port == 0 {
+		cfg.Port = 22
+	}
+
+	return &SSHJob{config: cfg, allowedRoots: allowedRoots}, nil
+}
+
+// Type returns the job type
+func (j *SSHJob) Type() string {
+	return "ssh"
+}
+
+// Description returns job description
+func (j *SSHJob) Description() string {
+	target := fmt.Sprintf("%s@%s:%d", j.config.User, j.config.Host, j.config.Port)
+	if j.config.Script != "" {
+		return fmt.Sprintf("Run script over SSH on %s", target)
+	}
+	commandPreview := j.config.Command
+	if len(commandPreview) > 50 {
+		commandPreview = commandPreview[:50] + "..."
+	}
+	return fmt.Sprintf("Run command over SSH on %s: %s", target, commandPreview)
+}
+
+// Validate validates the job configuration
+func (j *SSHJob) Validate() error {
+	if j.config.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if j.config.User == "" {
+		return fmt.Errorf("user is required")
+	}
+	if j.config.Port < 1 || j.config.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", j.config.Port)
+	}
+	if j.config.PrivateKeySecret == "" && j.config.PasswordSecret == "" {
+		return fmt.Errorf("private_key_secret or password_secret is required")
+	}
+	if (j.config.Command == "") == (j.config.Script == "") {
+		return fmt.Errorf("exactly one of command or script is required")
+	}
+	if len(j.config.Args) > 0 && j.config.Script == "" {
+		return fmt.Errorf("args are only supported with script")
+	}
+	for key := range j.config.Env {
+		if !domain.IsValidEnvName(key) {
+			return fmt.Errorf("invalid environment variable name: %s", key)
+		}
+	}
+	if j.config.KnownHostsFile != "" {
+		if err := checkAllowedPath("known_hosts_file", j.config.KnownHostsFile, j.allowedRoots); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Execute runs the command on the remote host
+func (j *SSHJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Set timeout if specified
+	if j.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(j.config.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	clientConfig, err := j.clientConfig()
+	if err != nil {
+		return sshFailure(ctx, fmt.Sprintf("Failed to configure SSH client: %v", err)), nil
+	}
+
+	address := net.JoinHostPort(j.config.Host, strconv.Itoa(j.config.Port))
+	client, err := dialSSH(ctx, address, clientConfig)
+	if err != nil {
+		return sshFailure(ctx, fmt.Sprintf("Failed to connect to %s: %v", address, err)), nil
+	}
+	defer func() { _ = client.Close() }()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return sshFailure(ctx, fmt.Sprintf("Failed to open SSH session: %v", err)), nil
+	}
+	defer func() { _ = session.Close() }()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	command := j.remoteCommand()
+	if j.config.Script != "" {
+		// Upload the script over stdin so nothing needs to be written on the remote host
+		session.Stdin = strings.NewReader(j.config.Script)
+	}
+
+	if err := session.Start(command); err != nil {
+		return sshFailure(ctx, fmt.Sprintf("Failed to start remote command: %v", err)), nil
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// Ask the remote process to stop, then tear down the session
+		_ = session.Signal(ssh.SIGTERM)
+		_ = session.Close()
+		_ = client.Close()
+		err = <-done
+	}
+
+	exitCode := 0
+	errorMsg := ""
+
+	if err != nil {
+		var exitErr *ssh.ExitError
+		var missingErr *ssh.ExitMissingError
+		if ctx.Err() == context.Canceled {
+			exitCode = 130 // SIGINT exit code
+			errorMsg = "Job cancelled by user"
+		} else if ctx.Err() == context.DeadlineExceeded {
+			exitCode = 124 // Timeout exit code
+			errorMsg = "Remote command timeout"
+		} else if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitStatus()
+			if exitErr.Signal() != "" {
+				errorMsg = fmt.Sprintf("Remote command killed by signal %s: %s", exitErr.Signal(), stderr.String())
+			} else {
+				errorMsg = fmt.Sprintf("Remote command exited with code %d: %s", exitCode, stderr.String())
+			}
+		} else if errors.As(err, &missingErr) {
+			exitCode = 1
+			errorMsg = "Remote command exited without reporting an exit status"
+		} else {
+			exitCode = 1
+			errorMsg = fmt.Sprintf("Failed to run remote command: %v", err)
+		}
+	}
+
+	// Combine stdout and stderr
+	output := stdout.String()
+	if stderr.Len() > 0 {
+		if output != "" {
+			output += "\n\n--- STDERR ---\n"
+		}
+		output += stderr.String()
+	}
+
+	return &domain.ExecutionResult{
+		Output:   output,
+		ExitCode: exitCode,
+		Error:    errorMsg,
+	}, nil
+}
+
+// clientConfig builds the SSH client configuration, resolving credentials from secrets
+func (j *SSHJob) clientConfig() (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+
+	if j.config.PrivateKeySecret != "" {
+		key, err := domain.ResolveSecret(j.config.PrivateKeySecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve private key: %w", err)
+		}
+
+		var signer ssh.Signer
+		if j.config.PassphraseSecret != "" {
+			passphrase, err := domain.ResolveSecret(j.config.PassphraseSecret)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve passphrase: %w", err)
+			}
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse private key: %w", err)
+			}
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(key))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse private key: %w", err)
+			}
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if j.config.PasswordSecret != "" {
+		password, err := domain.ResolveSecret(j.config.PasswordSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve password: %w", err)
+		}
+		auth = append(auth, ssh.Password(password))
+	}
+
+	hostKeyCallback, err := j.hostKeyCallback()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssh.ClientConfig{
+		User:            j.config.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}, nil
+}
+
+// hostKeyCallback verifies the server against known_hosts unless explicitly disabled
+func (j *SSHJob) hostKeyCallback() (ssh.HostKeyCallback, error) {
+	if j.config.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil //nolint:gosec // explicitly requested by the job config
+	}
+
+	var path string
+	if j.config.KnownHosts != "" {
+		// knownhosts only reads from files, so stage the inline content in a temp file
+		tmp, err := os.CreateTemp("", "oneoff-known-hosts-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to stage known_hosts: %w", err)
+		}
+		defer func() { _ = os.Remove(tmp.Name()) }()
+
+		if _, err := tmp.WriteString(j.config.KnownHosts); err != nil {
+			_ = tmp.Close()
+			return nil, fmt.Errorf("failed to stage known_hosts: %w", err)
+		}
+		if err := tmp.Close(); err != nil {
+			return nil, fmt.Errorf("failed to stage known_hosts: %w", err)
+		}
+		path = tmp.Name()
+	} else if j.config.KnownHostsFile != "" {
+		resolved, err := resolveAllowedPath("known_hosts_file", j.config.KnownHostsFile, j.allowedRoots)
+		if err != nil {
+			return nil, err
+		}
+		path = resolved
+	} else {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate known_hosts: %w", err)
+		}
+		path = filepath.Join(home, ".ssh", "known_hosts")
+	}
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// remoteCommand builds the command line executed by the remote shell
+func (j *SSHJob) remoteCommand() string {
+	var sb strings.Builder
+
+	// Exported inline because most servers reject SetEnv requests (AcceptEnv)
+	keys := make([]string, 0, len(j.config.Env))
+	for key := range j.config.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("export %s=%s; ", key, shellQuote(j.config.Env[key])))
+	}
+
+	if j.config.Script != "" {
+		sb.WriteString("/bin/sh -s --")
+		for _, arg := range j.config.Args {
+			sb.WriteString(" " + shellQuote(arg))
+		}
+		return sb.String()
+	}
+
+	sb.WriteString(j.config.Command)
+	return sb.String()
+}
+
+// dialSSH establishes an SSH connection that honors context cancellation
+func dialSSH(ctx context.Context, address string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Abort the handshake if the context ends first
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// sshFailure builds a failed result, distinguishing cancellation and timeouts
+func sshFailure(ctx context.Context, errorMsg string) *domain.ExecutionResult {
+	exitCode := 1
+	if ctx.Err() == context.Canceled {
+		exitCode = 130
+		errorMsg = "Job cancelled by user"
+	} else if ctx.Err() == context.DeadlineExceeded {
+		exitCode = 124
+		errorMsg = "Remote command timeout"
+	}
+
+	return &domain.ExecutionResult{
+		ExitCode: exitCode,
+		Error:    errorMsg,
+	}
+}
+
+// shellQuote quotes a value for safe use in a POSIX shell command line
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
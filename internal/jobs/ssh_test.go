@@ -0,0 +1,295 @@
+package jobs
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testSSHServer is an in-process SSH server that answers exec requests with a canned reply
+type testSSHServer struct {
+	addr    string
+	hostKey ssh.PublicKey
+
+	mu       sync.Mutex
+	commands []string
+	stdin    []string
+}
+
+// startTestSSHServer accepts connections authenticated with clientKey and replies to every command
+// with stdout and the exit status
+func startTestSSHServer(t *testing.T, clientKey ssh.PublicKey, stdout string, exitStatus uint32) *testSSHServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientKey.Marshal()) {
+				return nil, fmt.Errorf("unknown key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	server := &testSSHServer{addr: listener.Addr().String(), hostKey: hostSigner.PublicKey()}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, config, stdout, exitStatus)
+		}
+	}()
+	return server
+}
+
+// serve handles one client connection
+func (s *testSSHServer) serve(conn net.Conn, config *ssh.ServerConfig, stdout string, exitStatus uint32) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer func() { _ = channel.Close() }()
+			for req := range requests {
+				if req.Type != "exec" {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				var payload struct{ Command string }
+				_ = ssh.Unmarshal(req.Payload, &payload)
+				_ = req.Reply(true, nil)
+
+				stdin, _ := io.ReadAll(channel)
+				s.mu.Lock()
+				s.commands = append(s.commands, payload.Command)
+				s.stdin = append(s.stdin, string(stdin))
+				s.mu.Unlock()
+
+				_, _ = io.WriteString(channel, stdout)
+				status := make([]byte, 4)
+				binary.BigEndian.PutUint32(status, exitStatus)
+				_, _ = channel.SendRequest("exit-status", false, status)
+				return
+			}
+		}()
+	}
+}
+
+// recorded returns the commands and stdin received so far
+func (s *testSSHServer) recorded() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.commands...), append([]string{}, s.stdin...)
+}
+
+// newTestSSHKey returns a client key and the PEM encoding of its private half
+func newTestSSHKey(t *testing.T) (ssh.PublicKey, string) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer.PublicKey(), string(pem.EncodeToMemory(block))
+}
+
+func TestSSHJob(t *testing.T) {
+	clientKey, privatePEM := newTestSSHKey(t)
+	otherKey, _ := newTestSSHKey(t)
+	t.Setenv("ONEOFF_SECRET_TEST_SSH_KEY", privatePEM)
+	setTestSecretPolicy(t)
+
+	tests := []struct {
+		name       string
+		stdout     string
+		exitStatus uint32
+		config     string // command or script fields
+		hostKey    ssh.PublicKey
+		exitCode   int
+		contains   string // expected in the output, or in the error on failure
+		command    string // expected remote command, empty if none may run
+		stdin      string
+	}{
+		{"command", "deployed\n", 0, `"command":"./deploy.sh","env":{"RELEASE":"it's v2"}`, nil, 0, "deployed",
+			`export RELEASE='it'"'"'s v2'; ./deploy.sh`, ""},
+		{"script", "", 3, `"script":"echo start\nexit 3\n","args":["a b"]`, nil, 3, "exited with code 3",
+			"/bin/sh -s -- 'a b'", "echo start\nexit 3\n"},
+		{"unknown host key", "", 0, `"command":"true"`, otherKey, 1, "key mismatch", "", ""},
+	}
+	for _, tt := range tests {
+		server := startTestSSHServer(t, clientKey, tt.stdout, tt.exitStatus)
+		host, port, _ := net.SplitHostPort(server.addr)
+		hostKey := server.hostKey
+		if tt.hostKey != nil {
+			hostKey = tt.hostKey
+		}
+
+		config := `{"host":"` + host + `","port":` + port + `,"user":"deploy","private_key_secret":"env:ONEOFF_SECRET_TEST_SSH_KEY",` +
+			`"known_hosts":"` + knownhosts.Line([]string{server.addr}, hostKey) + `",` + tt.config + `}`
+		job, err := NewSSHJob(config, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		result, err := job.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		message := result.Output
+		if result.ExitCode != 0 {
+			message = result.Error
+		}
+		if result.ExitCode != tt.exitCode || !strings.Contains(message, tt.contains) {
+			t.Errorf("%s: exit %d, output %q, error %q; want exit %d with %q", tt.name, result.ExitCode, result.Output, result.Error, tt.exitCode, tt.contains)
+		}
+		commands, stdin := server.recorded()
+		if tt.command == "" {
+			if len(commands) != 0 {
+				t.Errorf("%s: command ran on an unverified host: %q", tt.name, commands)
+			}
+			continue
+		}
+		if len(commands) != 1 || commands[0] != tt.command || stdin[0] != tt.stdin {
+			t.Errorf("%s: remote commands %q with stdin %q; want %q with %q", tt.name, commands, stdin, tt.command, tt.stdin)
+		}
+	}
+}
+
+func TestSSHJobSecretPolicy(t *testing.T) {
+	clientKey, privatePEM := newTestSSHKey(t)
+	t.Setenv("ONEOFF_SECRET_TEST_SSH_KEY", privatePEM)
+	t.Setenv("DEPLOY_SSH_KEY", privatePEM)
+	setTestSecretPolicy(t)
+
+	server := startTestSSHServer(t, clientKey, "", 0)
+	host, port, _ := net.SplitHostPort(server.addr)
+	var messages []string
+	for _, ref := range []string{"env:DEPLOY_SSH_KEY", "env:ONEOFF_SECRET_MISSING", "file:/etc/passwd"} {
+		job, err := NewSSHJob(`{"host":"`+host+`","port":`+port+`,"user":"deploy","command":"true","private_key_secret":"`+ref+`",`+
+			`"known_hosts":"`+knownhosts.Line([]string{server.addr}, server.hostKey)+`"}`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := job.Execute(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ExitCode != 1 {
+			t.Fatalf("%s: expected the secret to be rejected, got exit %d", ref, result.ExitCode)
+		}
+		messages = append(messages, strings.ReplaceAll(result.Error, ref, "REF"))
+	}
+	if messages[0] != messages[1] || messages[1] != messages[2] {
+		t.Fatalf("errors reveal which secrets exist: %q", messages)
+	}
+	if commands, _ := server.recorded(); len(commands) != 0 {
+		t.Fatalf("command ran without valid credentials: %q", commands)
+	}
+}
+
+func TestSSHJobKnownHostsFile(t *testing.T) {
+	clientKey, privatePEM := newTestSSHKey(t)
+	t.Setenv("ONEOFF_SECRET_TEST_SSH_KEY", privatePEM)
+	setTestSecretPolicy(t)
+
+	server := startTestSSHServer(t, clientKey, "ok\n", 0)
+	host, port, _ := net.SplitHostPort(server.addr)
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	line := knownhosts.Line([]string{server.addr}, server.hostKey) + "\n"
+	for _, path := range []string{filepath.Join(root, "known_hosts"), filepath.Join(outside, "known_hosts")} {
+		if err := os.WriteFile(path, []byte(line), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(outside, "known_hosts"), filepath.Join(root, "linked")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		file     string
+		policy   string // expected policy violation
+		exitCode int
+		errMsg   string
+	}{
+		{"inside roots", filepath.Join(root, "known_hosts"), "", 0, ""},
+		{"outside roots", filepath.Join(outside, "known_hosts"), "known_hosts_file: path is outside the allowed roots", 0, ""},
+		{"symlink out of roots", filepath.Join(root, "linked"), "", 1, "known_hosts_file resolves outside the allowed roots"},
+	}
+	for _, tt := range tests {
+		config := `{"host":"` + host + `","port":` + port + `,"user":"deploy","command":"true",` +
+			`"private_key_secret":"env:ONEOFF_SECRET_TEST_SSH_KEY","known_hosts_file":"` + tt.file + `"}`
+		job, err := NewSSHJob(config, []string{root})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		result, err := job.Execute(context.Background())
+		if tt.policy != "" {
+			var violation *domain.PolicyViolation
+			if err == nil || !strings.Contains(err.Error(), tt.policy) || !errors.As(err, &violation) {
+				t.Errorf("%s: expected a policy violation containing %q, got %v", tt.name, tt.policy, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if result.ExitCode != tt.exitCode || !strings.Contains(result.Error, tt.errMsg) {
+			t.Errorf("%s: exit %d, error %q; want exit %d, error containing %q", tt.name, result.ExitCode, result.Error, tt.exitCode, tt.errMsg)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// jobStep is one named command of a multi-step job
+type jobStep struct {
+	name            string
+	continueOnError bool
+	timeout         int
+	run             func(ctx context.Context) (stdout, stderr string, usage *domain.ResourceUsage, err error)
+}
+
+// validateStepNames checks that every step has a unique name
+func validateStepNames(names []string) error {
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		if name == "" {
+			return fmt.Errorf("steps[%d].name is required", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate step name: %s", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// runSteps runs the steps in order, stopping at the first failure that is not allowed to continue
+func runSteps(ctx context.Context, steps []jobStep, timeoutMsg string) *domain.ExecutionResult {
+	var output strings.Builder
+	results := make([]domain.ExecutionStep, 0, len(steps))
+	var fatal *domain.ExecutionStep
+	var usage *domain.ResourceUsage
+	outputs := map[string]string{}
+
+	for i, step := range steps {
+		output.WriteString(fmt.Sprintf("=== Step %d/%d: %s ===\n", i+1, len(steps), step.name))
+
+		if fatal != nil {
+			output.WriteString("--> skipped\n\n")
+			results = append(results, domain.ExecutionStep{
+				Name:            step.name,
+				Status:          domain.StepStatusSkipped,
+				ContinueOnError: step.continueOnError,
+			})
+			continue
+		}
+
+		stepCtx := ctx
+		cancel := context.CancelFunc(func() {})
+		if step.timeout > 0 {
+			stepCtx, cancel = context.WithTimeout(ctx, time.Duration(step.timeout)*time.Second)
+		}
+
+		start := time.Now()
+		stdout, stderr, stepUsage, err := step.run(stepCtx)
+		stepErr := stepCtx.Err()
+		cancel()
+		parseSetOutputs(stdout, outputs)
+
+		result := domain.ExecutionStep{
+			Name:            step.name,
+			Status:          domain.StepStatusCompleted,
+			Output:          stdout,
+			DurationMs:      time.Since(start).Milliseconds(),
+			Usage:           stepUsage,
+			ContinueOnError: step.continueOnError,
+		}
+		if stepUsage != nil {
+			if usage == nil {
+				usage = &domain.ResourceUsage{}
+			}
+			usage.Add(stepUsage)
+		}
+		if stderr != "" {
+			if result.Output != "" {
+				result.Output += "\n\n--- STDERR ---\n"
+			}
+			result.Output += stderr
+		}
+
+		if err != nil {
+			result.Status = domain.StepStatusFailed
+			if ctx.Err() == context.Canceled {
+				result.Status = domain.StepStatusCancelled
+				result.ExitCode = 130
+				result.Error = "Step cancelled by user"
+			} else if ctx.Err() == context.DeadlineExceeded {
+				result.ExitCode = 124
+				result.Error = timeoutMsg
+			} else if stepErr == context.DeadlineExceeded {
+				result.ExitCode = 124
+				result.Error = "Step timeout"
+			} else if limitErr, ok := err.(*resourceLimitError); ok {
+				result.ExitCode = limitErr.exitCode
+				result.Error = fmt.Sprintf("Resource limit exceeded: %s", limitErr.reason)
+			} else if exitErr, ok := err.(*exec.ExitError); ok {
+				result.ExitCode = exitErr.ExitCode()
+				result.Error = fmt.Sprintf("Step exited with code %d", result.ExitCode)
+			} else {
+				result.ExitCode = 1
+				result.Error = fmt.Sprintf("Failed to run step: %v", err)
+			}
+		}
+
+		output.WriteString(result.Output)
+		if result.Output != "" && !strings.HasSuffix(result.Output, "\n") {
+			output.WriteString("\n")
+		}
+		output.WriteString(fmt.Sprintf("--> %s (exit code %d, %dms)", result.Status, result.ExitCode, result.DurationMs))
+		if result.Status == domain.StepStatusFailed && step.continueOnError && ctx.Err() == nil {
+			output.WriteString(", continuing")
+		}
+		output.WriteString("\n\n")
+
+		results = append(results, result)
+		if result.Status != domain.StepStatusCompleted && (!step.continueOnError || ctx.Err() != nil) {
+			fatal = &results[len(results)-1]
+		}
+	}
+
+	if len(outputs) == 0 {
+		outputs = nil
+	}
+
+	if ctx.Err() == context.Canceled {
+		return &domain.ExecutionResult{
+			Output:   output.String(),
+			ExitCode: 130,
+			Error:    "Job cancelled by user",
+			Steps:    results,
+			Usage:    usage,
+			Outputs:  outputs,
+		}
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return &domain.ExecutionResult{
+			Output:   output.String(),
+			ExitCode: 124,
+			Error:    timeoutMsg,
+			Steps:    results,
+			Usage:    usage,
+			Outputs:  outputs,
+		}
+	}
+	if fatal != nil {
+		return &domain.ExecutionResult{
+			Output:   output.String(),
+			ExitCode: fatal.ExitCode,
+			Error:    fmt.Sprintf("Step %q failed: %s", fatal.Name, fatal.Error),
+			Steps:    results,
+			Usage:    usage,
+			Outputs:  outputs,
+		}
+	}
+
+	return &domain.ExecutionResult{
+		Output:   output.String(),
+		ExitCode: 0,
+		Steps:    results,
+		Usage:    usage,
+		Outputs:  outputs,
+	}
+}
@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// buildTLSConfig builds a client TLS configuration from PEM-encoded material
+func buildTLSConfig(caCert, clientCert, clientKey, serverName string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: insecureSkipVerify, //nolint:gosec // explicitly requested by the job config
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("failed to parse CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			return nil, fmt.Errorf("client certificate and key must be provided together")
+		}
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package jobs
+
+import (
+	"os"
+
+	"github.com/meysam81/oneoff/internal/domain"
+)
+
+// processUsage is not measured outside Unix
+func processUsage(*os.ProcessState) *domain.ResourceUsage {
+	return nil
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package jobs
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"golang.org/x/sys/unix"
+)
+
+// processUsage reads the resources used by a finished process and the children it waited for
+func processUsage(state *os.ProcessState) *domain.ResourceUsage {
+	if state == nil {
+		return nil
+	}
+	rusage, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || rusage == nil {
+		return nil
+	}
+
+	usage := &domain.ResourceUsage{
+		CPUUserMs:   time.Duration(rusage.Utime.Nano()).Milliseconds(),
+		CPUSystemMs: time.Duration(rusage.Stime.Nano()).Milliseconds(),
+		MaxRSSKB:    int64(rusage.Maxrss),
+		// Block operations are counted in 512-byte units
+		ReadBytes:  int64(rusage.Inblock) * 512,
+		WriteBytes: int64(rusage.Oublock) * 512,
+	}
+	if runtime.GOOS == "darwin" || runtime.GOOS == "ios" {
+		// Darwin reports the peak resident set size in bytes
+		usage.MaxRSSKB /= 1024
+	}
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		usage.ExitSignal = unix.SignalName(status.Signal())
+	}
+
+	return usage
+}
@@ -0,0 +1,285 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+const (
+	// wasmScratchMount is the only directory visible to modules
+	wasmScratchMount = "/scratch"
+
+	wasmDefaultMemoryLimitMB = 64
+	wasmMaxModuleSize        = 64 << 20
+	wasmPageSize             = 64 << 10
+
+	// A module always runs with a deadline, since loops without calls are not metered
+	wasmDefaultTimeout = 300
+	wasmMaxTimeout     = 3600
+)
+
+// errCallBudgetExhausted is the cancellation cause used when a module exceeds its call budget
+var errCallBudgetExhausted = errors.New("call budget exhausted")
+
+// WasmJob implements JobExecutor for sandboxed WebAssembly modules
+type WasmJob struct {
+	config *domain.WasmJobConfig
+}
+
+// NewWasmJob creates a new WebAssembly job
+func NewWasmJob(config string) (domain.JobExecutor, error) {
+	cfg, err := domain.ParseWasmJobConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid wasm job config: %w", err)
+	}
+
+	if cfg.MemoryLimitMB == 0 {
+		cfg.MemoryLimitMB = wasmDefaultMemoryLimitMB
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = wasmDefaultTimeout
+	}
+
+	return &WasmJob{config: cfg}, nil
+}
+
+// Type returns the job type
+func (j *WasmJob) Type() string {
+	return "wasm"
+}
+
+// Description returns job description
+func (j *WasmJob) Description() string {
+	if j.config.ModuleURL != "" {
+		return fmt.Sprintf("Run WebAssembly module: %s", j.config.ModuleURL)
+	}
+	return "Run uploaded WebAssembly module"
+}
+
+// Validate validates the job configuration
+func (j *WasmJob) Validate() error {
+	if (j.config.Module == "") == (j.config.ModuleURL == "") {
+		return fmt.Errorf("exactly one of module or module_url is required")
+	}
+	if j.config.ModuleURL != "" {
+		if !strings.HasPrefix(j.config.ModuleURL, "https://") && !strings.HasPrefix(j.config.ModuleURL, "http://") {
+			return fmt.Errorf("module_url must be an http(s) URL")
+		}
+		if j.config.SHA256 == "" {
+			return fmt.Errorf("sha256 is required when using module_url")
+		}
+	}
+	if j.config.SHA256 != "" {
+		if _, err := hex.DecodeString(j.config.SHA256); err != nil || len(j.config.SHA256) != sha256.Size*2 {
+			return fmt.Errorf("sha256 must be a hex-encoded SHA-256 digest")
+		}
+	}
+	if j.config.MemoryLimitMB < 1 || j.config.MemoryLimitMB > 4096 {
+		return fmt.Errorf("memory_limit_mb must be between 1 and 4096")
+	}
+	if j.config.CallBudget < 0 {
+		return fmt.Errorf("call_budget cannot be negative")
+	}
+	if j.config.Timeout < 1 || j.config.Timeout > wasmMaxTimeout {
+		return fmt.Errorf("timeout must be between 1 and %d seconds", wasmMaxTimeout)
+	}
+	return nil
+}
+
+// Execute runs the module in an embedded WASI runtime
+func (j *WasmJob) Execute(ctx context.Context) (*domain.ExecutionResult, error) {
+	if err := j.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(j.config.Timeout)*time.Second)
+	defer cancel()
+
+	wasm, err := j.loadModule(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return wasmFailure(ctx, ""), nil
+		}
+		return nil, err
+	}
+
+	scratch, err := os.MkdirTemp("", "oneoff-wasm-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(scratch) }()
+
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	defer cancelRun(nil)
+
+	// The budget is charged per function call. wazero has no instruction metering, so loops without
+	// calls are only bounded by the timeout.
+	compileCtx := runCtx
+	if j.config.CallBudget > 0 {
+		remaining := j.config.CallBudget
+		compileCtx = experimental.WithFunctionListenerFactory(runCtx, experimental.FunctionListenerFactoryFunc(
+			func(api.FunctionDefinition) experimental.FunctionListener {
+				return experimental.FunctionListenerFunc(func(context.Context, api.Module, api.FunctionDefinition, []uint64, experimental.StackIterator) {
+					if atomic.AddInt64(&remaining, -1) < 0 {
+						// Record the cause, then abort the call immediately; wazero turns the panic into an error
+						cancelRun(errCallBudgetExhausted)
+						panic(errCallBudgetExhausted)
+					}
+				})
+			}))
+	}
+
+	runtimeConfig := wazero.NewRuntimeConfig().
+		WithCloseOnContextDone(true).
+		WithMemoryLimitPages(uint32(j.config.MemoryLimitMB * (1 << 20) / wasmPageSize))
+	wasmRuntime := wazero.NewRuntimeWithConfig(runCtx, runtimeConfig)
+	defer func() { _ = wasmRuntime.Close(context.Background()) }()
+
+	wasi_snapshot_preview1.MustInstantiate(runCtx, wasmRuntime)
+
+	compiled, err := wasmRuntime.CompileModule(compileCtx, wasm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile module: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	moduleConfig := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(append([]string{"module"}, j.config.Args...)...).
+		WithStdout(&stdout).
+		WithStderr(&stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader).
+		WithFSConfig(wazero.NewFSConfig().WithDirMount(scratch, wasmScratchMount))
+
+	keys := make([]string, 0, len(j.config.Env))
+	for key := range j.config.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		moduleConfig = moduleConfig.WithEnv(key, j.config.Env[key])
+	}
+
+	_, err = wasmRuntime.InstantiateModule(runCtx, compiled, moduleConfig)
+
+	exitCode := 0
+	errorMsg := ""
+
+	if context.Cause(runCtx) == errCallBudgetExhausted {
+		exitCode = 1
+		errorMsg = fmt.Sprintf("Module exceeded its call budget (%d function calls)", j.config.CallBudget)
+	} else if err != nil {
+		var exitErr *sys.ExitError
+		if ctx.Err() != nil {
+			result := wasmFailure(ctx, "")
+			exitCode, errorMsg = result.ExitCode, result.Error
+		} else if errors.As(err, &exitErr) {
+			exitCode = int(exitErr.ExitCode())
+			if exitCode != 0 {
+				errorMsg = fmt.Sprintf("Module exited with code %d: %s", exitCode, stderr.String())
+			}
+		} else {
+			exitCode = 1
+			errorMsg = fmt.Sprintf("Module trapped: %v", err)
+		}
+	}
+
+	// Combine stdout and stderr
+	output := stdout.String()
+	if stderr.Len() > 0 {
+		if output != "" {
+			output += "\n\n--- STDERR ---\n"
+		}
+		output += stderr.String()
+	}
+
+	return &domain.ExecutionResult{
+		Output:   output,
+		ExitCode: exitCode,
+		Error:    errorMsg,
+	}, nil
+}
+
+// loadModule decodes or downloads the module and verifies its checksum
+func (j *WasmJob) loadModule(ctx context.Context) ([]byte, error) {
+	var wasm []byte
+
+	if j.config.Module != "" {
+		decoded, err := base64.StdEncoding.DecodeString(j.config.Module)
+		if err != nil {
+			return nil, fmt.Errorf("module is not valid base64: %w", err)
+		}
+		wasm = decoded
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.config.ModuleURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid module_url: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch module: %w", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch module: unexpected status %d", resp.StatusCode)
+		}
+
+		wasm, err = io.ReadAll(io.LimitReader(resp.Body, wasmMaxModuleSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch module: %w", err)
+		}
+	}
+
+	if len(wasm) > wasmMaxModuleSize {
+		return nil, fmt.Errorf("module exceeds maximum size of %d bytes", wasmMaxModuleSize)
+	}
+
+	if j.config.SHA256 != "" {
+		sum := sha256.Sum256(wasm)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), j.config.SHA256) {
+			return nil, fmt.Errorf("module checksum mismatch")
+		}
+	}
+
+	return wasm, nil
+}
+
+// wasmFailure builds a failed result for a cancelled or timed out module
+func wasmFailure(ctx context.Context, errorMsg string) *domain.ExecutionResult {
+	exitCode := 1
+	if ctx.Err() == context.Canceled {
+		exitCode = 130
+		errorMsg = "Job cancelled by user"
+	} else if ctx.Err() == context.DeadlineExceeded {
+		exitCode = 124
+		errorMsg = "Module execution timeout"
+	}
+
+	return &domain.ExecutionResult{
+		ExitCode: exitCode,
+		Error:    errorMsg,
+	}
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wasmInfiniteLoop is a module whose _start is `loop br 0 end`, which makes no calls
+var wasmInfiniteLoop = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00, // magic, version
+	0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type section: func () -> ()
+	0x03, 0x02, 0x01, 0x00, // function section: one function of type 0
+	0x07, 0x0a, 0x01, 0x06, '_', 's', 't', 'a', 'r', 't', 0x00, 0x00, // export section: _start
+	0x0a, 0x09, 0x01, 0x07, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b, // code section: loop br 0 end end
+}
+
+func TestWasmJobTimeout(t *testing.T) {
+	module := base64.StdEncoding.EncodeToString(wasmInfiniteLoop)
+
+	tests := []struct {
+		name     string
+		config   string
+		errMsg   string // expected validation error
+		exitCode int
+	}{
+		{"too long", `{"module":"` + module + `","timeout":7200}`, "timeout must be between 1 and 3600 seconds", 0},
+		{"infinite loop", `{"module":"` + module + `","call_budget":10,"timeout":1}`, "", 124},
+	}
+	for _, tt := range tests {
+		job, err := NewWasmJob(tt.config)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tt.errMsg != "" {
+			if err := job.Validate(); err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.errMsg, err)
+			}
+			continue
+		}
+
+		start := time.Now()
+		result, err := job.Execute(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if result.ExitCode != tt.exitCode {
+			t.Errorf("%s: exit %d, error %q; want exit %d", tt.name, result.ExitCode, result.Error, tt.exitCode)
+		}
+		if elapsed := time.Since(start); elapsed > 10*time.Second {
+			t.Errorf("%s: module was not stopped, ran for %s", tt.name, elapsed)
+		}
+	}
+
+	job, err := NewWasmJob(`{"module":"` + module + `"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeout := job.(*WasmJob).config.Timeout; timeout != wasmDefaultTimeout {
+		t.Fatalf("modules without a timeout run for %ds, want the default %ds", timeout, wasmDefaultTimeout)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/meysam81/oneoff/internal/domain"
 )
 
 // Collector is the interface for metrics collection
@@ -15,6 +17,7 @@ type Collector interface {
 	// Job metrics
 	IncJobsTotal(jobType, status string)
 	ObserveJobDuration(jobType string, duration time.Duration)
+	ObserveJobUsage(jobType, projectID string, usage *domain.ResourceUsage)
 
 	// Worker metrics
 	SetActiveWorkers(count int)
@@ -42,6 +45,8 @@ type PrometheusCollector struct {
 	// Job metrics
 	jobsTotal    map[string]*int64  // key: type:status
 	jobDurations map[string]*bucket // key: type
+	jobUsage     map[string]*usage  // key: type:project
+	jobSignals   map[string]*int64  // key: type:project:signal
 
 	// Worker metrics
 	activeWorkers int64
@@ -71,11 +76,24 @@ type bucket struct {
 	mu    sync.Mutex
 }
 
+// usage accumulates the resources consumed by jobs
+type usage struct {
+	cpuUserSeconds   float64
+	cpuSystemSeconds float64
+	readBytes        int64
+	writeBytes       int64
+	maxRSSCount      int64
+	maxRSSSum        float64 // peak resident set size in bytes
+	mu               sync.Mutex
+}
+
 // NewCollector creates a new metrics collector
 func NewCollector() *PrometheusCollector {
 	return &PrometheusCollector{
 		jobsTotal:        make(map[string]*int64),
 		jobDurations:     make(map[string]*bucket),
+		jobUsage:         make(map[string]*usage),
+		jobSignals:       make(map[string]*int64),
 		requestsTotal:    make(map[string]*int64),
 		requestDurations: make(map[string]*bucket),
 		startTime:        time.Now(),
@@ -109,6 +127,38 @@ func (c *PrometheusCollector) ObserveJobDuration(jobType string, duration time.D
 	b.mu.Unlock()
 }
 
+// ObserveJobUsage records the resources consumed by a job execution
+func (c *PrometheusCollector) ObserveJobUsage(jobType, projectID string, ru *domain.ResourceUsage) {
+	key := fmt.Sprintf("%s:%s", jobType, projectID)
+	c.mu.Lock()
+	if c.jobUsage[key] == nil {
+		c.jobUsage[key] = &usage{}
+	}
+	u := c.jobUsage[key]
+	var signals *int64
+	if ru.ExitSignal != "" {
+		signalKey := fmt.Sprintf("%s:%s", key, ru.ExitSignal)
+		if c.jobSignals[signalKey] == nil {
+			c.jobSignals[signalKey] = new(int64)
+		}
+		signals = c.jobSignals[signalKey]
+	}
+	c.mu.Unlock()
+
+	u.mu.Lock()
+	u.cpuUserSeconds += float64(ru.CPUUserMs) / 1000
+	u.cpuSystemSeconds += float64(ru.CPUSystemMs) / 1000
+	u.readBytes += ru.ReadBytes
+	u.writeBytes += ru.WriteBytes
+	u.maxRSSCount++
+	u.maxRSSSum += float64(ru.MaxRSSKB * 1024)
+	u.mu.Unlock()
+
+	if signals != nil {
+		atomic.AddInt64(signals, 1)
+	}
+}
+
 // SetActiveWorkers sets the current number of active workers
 func (c *PrometheusCollector) SetActiveWorkers(count int) {
 	atomic.StoreInt64(&c.activeWorkers, int64(count))
@@ -239,6 +289,69 @@ func (c *PrometheusCollector) Handler() http.Handler {
 		c.mu.RUnlock()
 		sb.WriteString("\n")
 
+		// Job resource usage
+		c.mu.RLock()
+		usageKeys := make([]string, 0, len(c.jobUsage))
+		for k := range c.jobUsage {
+			usageKeys = append(usageKeys, k)
+		}
+		sort.Strings(usageKeys)
+		var cpuLines, ioLines, rssLines strings.Builder
+		for _, key := range usageKeys {
+			parts := strings.SplitN(key, ":", 2)
+			if len(parts) == 2 {
+				u := c.jobUsage[key]
+				u.mu.Lock()
+				cpuLines.WriteString(fmt.Sprintf("oneoff_job_cpu_seconds_total{type=\"%s\",project=\"%s\",mode=\"user\"} %f\n",
+					parts[0], parts[1], u.cpuUserSeconds))
+				cpuLines.WriteString(fmt.Sprintf("oneoff_job_cpu_seconds_total{type=\"%s\",project=\"%s\",mode=\"system\"} %f\n",
+					parts[0], parts[1], u.cpuSystemSeconds))
+				ioLines.WriteString(fmt.Sprintf("oneoff_job_io_bytes_total{type=\"%s\",project=\"%s\",direction=\"read\"} %d\n",
+					parts[0], parts[1], u.readBytes))
+				ioLines.WriteString(fmt.Sprintf("oneoff_job_io_bytes_total{type=\"%s\",project=\"%s\",direction=\"write\"} %d\n",
+					parts[0], parts[1], u.writeBytes))
+				rssLines.WriteString(fmt.Sprintf("oneoff_job_max_rss_bytes_count{type=\"%s\",project=\"%s\"} %d\n",
+					parts[0], parts[1], u.maxRSSCount))
+				rssLines.WriteString(fmt.Sprintf("oneoff_job_max_rss_bytes_sum{type=\"%s\",project=\"%s\"} %f\n",
+					parts[0], parts[1], u.maxRSSSum))
+				u.mu.Unlock()
+			}
+		}
+		signalKeys := make([]string, 0, len(c.jobSignals))
+		for k := range c.jobSignals {
+			signalKeys = append(signalKeys, k)
+		}
+		sort.Strings(signalKeys)
+		var signalLines strings.Builder
+		for _, key := range signalKeys {
+			parts := strings.SplitN(key, ":", 3)
+			if len(parts) == 3 {
+				signalLines.WriteString(fmt.Sprintf("oneoff_job_signals_total{type=\"%s\",project=\"%s\",signal=\"%s\"} %d\n",
+					parts[0], parts[1], parts[2], atomic.LoadInt64(c.jobSignals[key])))
+			}
+		}
+		c.mu.RUnlock()
+
+		sb.WriteString("# HELP oneoff_job_cpu_seconds_total CPU time consumed by jobs in seconds\n")
+		sb.WriteString("# TYPE oneoff_job_cpu_seconds_total counter\n")
+		sb.WriteString(cpuLines.String())
+		sb.WriteString("\n")
+
+		sb.WriteString("# HELP oneoff_job_io_bytes_total Bytes read and written by jobs\n")
+		sb.WriteString("# TYPE oneoff_job_io_bytes_total counter\n")
+		sb.WriteString(ioLines.String())
+		sb.WriteString("\n")
+
+		sb.WriteString("# HELP oneoff_job_max_rss_bytes Peak resident memory of job executions in bytes\n")
+		sb.WriteString("# TYPE oneoff_job_max_rss_bytes summary\n")
+		sb.WriteString(rssLines.String())
+		sb.WriteString("\n")
+
+		sb.WriteString("# HELP oneoff_job_signals_total Job processes terminated by a signal\n")
+		sb.WriteString("# TYPE oneoff_job_signals_total counter\n")
+		sb.WriteString(signalLines.String())
+		sb.WriteString("\n")
+
 		// HTTP request totals
 		sb.WriteString("# HELP oneoff_http_requests_total Total number of HTTP requests\n")
 		sb.WriteString("# TYPE oneoff_http_requests_total counter\n")
@@ -307,15 +420,16 @@ func (c *PrometheusCollector) Handler() http.Handler {
 // NoopCollector is a no-op metrics collector for when metrics are disabled
 type NoopCollector struct{}
 
-func (n *NoopCollector) IncJobsTotal(jobType, status string)                                {}
-func (n *NoopCollector) ObserveJobDuration(jobType string, duration time.Duration)          {}
-func (n *NoopCollector) SetActiveWorkers(count int)                                         {}
-func (n *NoopCollector) SetTotalWorkers(count int)                                          {}
-func (n *NoopCollector) SetQueuedJobs(count int)                                            {}
-func (n *NoopCollector) IncRequestsTotal(method, path string, status int)                   {}
-func (n *NoopCollector) ObserveRequestDuration(method, path string, duration time.Duration) {}
-func (n *NoopCollector) IncAPIKeyValidations(valid bool)                                    {}
-func (n *NoopCollector) IncWebhookDeliveries(success bool)                                  {}
+func (n *NoopCollector) IncJobsTotal(jobType, status string)                                    {}
+func (n *NoopCollector) ObserveJobDuration(jobType string, duration time.Duration)              {}
+func (n *NoopCollector) ObserveJobUsage(jobType, projectID string, usage *domain.ResourceUsage) {}
+func (n *NoopCollector) SetActiveWorkers(count int)                                             {}
+func (n *NoopCollector) SetTotalWorkers(count int)                                              {}
+func (n *NoopCollector) SetQueuedJobs(count int)                                                {}
+func (n *NoopCollector) IncRequestsTotal(method, path string, status int)                       {}
+func (n *NoopCollector) ObserveRequestDuration(method, path string, duration time.Duration)     {}
+func (n *NoopCollector) IncAPIKeyValidations(valid bool)                                        {}
+func (n *NoopCollector) IncWebhookDeliveries(success bool)                                      {}
 func (n *NoopCollector) Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
@@ -23,13 +23,18 @@ type Repository interface {
 	ListExecutions(ctx context.Context, filter domain.ExecutionFilter) ([]*domain.JobExecution, error)
 	UpdateExecution(ctx context.Context, id string, status domain.ExecutionStatus, output, error string, exitCode *int) error
 	CompleteExecution(ctx context.Context, id string, status domain.ExecutionStatus, output, error string, exitCode *int, durationMs int64) error
+	SaveExecutionSteps(ctx context.Context, id string, steps []domain.ExecutionStep) error
+	SaveExecutionEnvKeys(ctx context.Context, id string, keys []string) error
+	SaveExecutionUsage(ctx context.Context, id string, usage *domain.ResourceUsage) error
+	SaveExecutionOutputs(ctx context.Context, id string, outputs map[string]string) error
 	DeleteOldExecutions(ctx context.Context, before time.Time) (int64, error)
+	FailInterruptedExecutions(ctx context.Context, errorMsg string) (int64, error)
 
 	// Project operations
 	CreateProject(ctx context.Context, project *domain.Project) error
 	GetProject(ctx context.Context, id string) (*domain.Project, error)
 	ListProjects(ctx context.Context, includeArchived bool) ([]*domain.Project, error)
-	UpdateProject(ctx context.Context, id string, name, description, color, icon *string, isArchived *bool) error
+	UpdateProject(ctx context.Context, id string, name, description, color, icon *string, env map[string]string, isArchived *bool) error
 	DeleteProject(ctx context.Context, id string) error
 
 	// Tag operations
@@ -66,6 +71,10 @@ type Repository interface {
 	// Scheduler operations
 	GetScheduledJobs(ctx context.Context, before time.Time, limit int) ([]*domain.Job, error)
 	UpdateJobStatus(ctx context.Context, id string, status domain.JobStatus) error
+	RescheduleJob(ctx context.Context, id string, scheduledAt time.Time) error
+	QueueRun(ctx context.Context, jobID string, params map[string]any) error
+	TakeQueuedRun(ctx context.Context, jobID string) (map[string]any, error)
+	DeleteQueuedRun(ctx context.Context, jobID string) error
 
 	// API Key operations
 	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -45,15 +46,20 @@ func (r *SQLiteRepository) CreateJob(ctx context.Context, job *domain.Job, tagID
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	parameters, err := encodeJobParameters(job.Parameters)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO jobs (name, type, config, scheduled_at, priority, project_id, timezone, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO jobs (name, type, config, scheduled_at, priority, project_id, timezone, status, parameters)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		RETURNING id, created_at, updated_at
 	`
 
 	err = tx.QueryRowContext(ctx, query,
 		job.Name, job.Type, job.Config, job.ScheduledAt.UTC(),
-		job.Priority, job.ProjectID, job.Timezone, job.Status,
+		job.Priority, job.ProjectID, job.Timezone, job.Status, parameters,
 	).Scan(&job.ID, &job.CreatedAt, &job.UpdatedAt)
 
 	if err != nil {
@@ -76,18 +82,19 @@ func (r *SQLiteRepository) CreateJob(ctx context.Context, job *domain.Job, tagID
 // GetJob retrieves a job by ID
 func (r *SQLiteRepository) GetJob(ctx context.Context, id string) (*domain.Job, error) {
 	query := `
-		SELECT id, name, type, config, scheduled_at, priority, project_id, timezone, status, created_at, updated_at
+		SELECT id, name, type, config, scheduled_at, priority, project_id, timezone, status, created_at, updated_at, parameters
 		FROM jobs
 		WHERE id = ?
 	`
 
 	job := &domain.Job{}
 	var scheduledAt, createdAt, updatedAt string
+	var parameters sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&job.ID, &job.Name, &job.Type, &job.Config, &scheduledAt,
 		&job.Priority, &job.ProjectID, &job.Timezone, &job.Status,
-		&createdAt, &updatedAt,
+		&createdAt, &updatedAt, &parameters,
 	)
 
 	if err == sql.ErrNoRows {
@@ -100,6 +107,7 @@ func (r *SQLiteRepository) GetJob(ctx context.Context, id string) (*domain.Job,
 	job.ScheduledAt = parseSQLiteTime(scheduledAt)
 	job.CreatedAt = parseSQLiteTime(createdAt)
 	job.UpdatedAt = parseSQLiteTime(updatedAt)
+	decodeJobParameters(job, parameters)
 
 	// Load tags
 	tags, err := r.GetJobTags(ctx, id)
@@ -113,7 +121,7 @@ func (r *SQLiteRepository) GetJob(ctx context.Context, id string) (*domain.Job,
 
 // ListJobs retrieves jobs based on filter
 func (r *SQLiteRepository) ListJobs(ctx context.Context, filter domain.JobFilter) ([]*domain.Job, error) {
-	query := `SELECT id, name, type, config, scheduled_at, priority, project_id, timezone, status, created_at, updated_at FROM jobs WHERE 1=1`
+	query := `SELECT id, name, type, config, scheduled_at, priority, project_id, timezone, status, created_at, updated_at, parameters FROM jobs WHERE 1=1`
 	args := []interface{}{}
 
 	if filter.ProjectID != "" {
@@ -187,11 +195,12 @@ func (r *SQLiteRepository) ListJobs(ctx context.Context, filter domain.JobFilter
 	for rows.Next() {
 		job := &domain.Job{}
 		var scheduledAt, createdAt, updatedAt string
+		var parameters sql.NullString
 
 		err := rows.Scan(
 			&job.ID, &job.Name, &job.Type, &job.Config, &scheduledAt,
 			&job.Priority, &job.ProjectID, &job.Timezone, &job.Status,
-			&createdAt, &updatedAt,
+			&createdAt, &updatedAt, &parameters,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
@@ -200,6 +209,7 @@ func (r *SQLiteRepository) ListJobs(ctx context.Context, filter domain.JobFilter
 		job.ScheduledAt = parseSQLiteTime(scheduledAt)
 		job.CreatedAt = parseSQLiteTime(createdAt)
 		job.UpdatedAt = parseSQLiteTime(updatedAt)
+		decodeJobParameters(job, parameters)
 
 		// Load tags
 		tags, err := r.GetJobTags(ctx, job.ID)
@@ -251,6 +261,14 @@ func (r *SQLiteRepository) UpdateJob(ctx context.Context, id string, updates dom
 		sets = append(sets, "status = ?")
 		args = append(args, *updates.Status)
 	}
+	if updates.Parameters != nil {
+		parameters, err := encodeJobParameters(updates.Parameters)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, "parameters = ?")
+		args = append(args, parameters)
+	}
 
 	if len(sets) == 0 && len(updates.TagIDs) == 0 {
 		return nil // Nothing to update
@@ -350,3 +368,22 @@ func parseSQLiteTime(timeStr string) time.Time {
 
 	return time.Time{}
 }
+
+// encodeJobParameters encodes a job's parameter declarations, storing NULL when there are none
+func encodeJobParameters(params []domain.JobParameter) (sql.NullString, error) {
+	if len(params) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode job parameters: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeJobParameters fills in a job's parameter declarations
+func decodeJobParameters(job *domain.Job, parameters sql.NullString) {
+	if parameters.Valid {
+		_ = json.Unmarshal([]byte(parameters.String), &job.Parameters)
+	}
+}
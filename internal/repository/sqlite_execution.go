@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -11,9 +12,18 @@ import (
 
 // CreateExecution creates a new job execution
 func (r *SQLiteRepository) CreateExecution(ctx context.Context, execution *domain.JobExecution) error {
+	var params sql.NullString
+	if len(execution.Params) > 0 {
+		data, err := json.Marshal(execution.Params)
+		if err != nil {
+			return fmt.Errorf("failed to encode execution params: %w", err)
+		}
+		params = sql.NullString{String: string(data), Valid: true}
+	}
+
 	query := `
-		INSERT INTO job_executions (job_id, started_at, status, created_at)
-		VALUES (?, ?, ?, ?)
+		INSERT INTO job_executions (job_id, started_at, status, created_at, params)
+		VALUES (?, ?, ?, ?, ?)
 		RETURNING id
 	`
 
@@ -28,13 +38,14 @@ func (r *SQLiteRepository) CreateExecution(ctx context.Context, execution *domai
 		execution.StartedAt.UTC(),
 		execution.Status,
 		execution.CreatedAt,
+		params,
 	).Scan(&execution.ID)
 }
 
 // GetExecution retrieves an execution by ID
 func (r *SQLiteRepository) GetExecution(ctx context.Context, id string) (*domain.JobExecution, error) {
 	query := `
-		SELECT id, job_id, started_at, completed_at, status, output, exit_code, error, duration_ms, created_at
+		SELECT id, job_id, started_at, completed_at, status, output, exit_code, error, duration_ms, steps, env_keys, usage, params, outputs, created_at
 		FROM job_executions
 		WHERE id = ?
 	`
@@ -42,7 +53,7 @@ func (r *SQLiteRepository) GetExecution(ctx context.Context, id string) (*domain
 	execution := &domain.JobExecution{}
 	var startedAt, createdAt string
 	var completedAt sql.NullString
-	var output, errorStr sql.NullString
+	var output, errorStr, steps, envKeys, usage, params, outputs sql.NullString
 	var exitCode sql.NullInt64
 	var durationMs sql.NullInt64
 
@@ -56,6 +67,11 @@ func (r *SQLiteRepository) GetExecution(ctx context.Context, id string) (*domain
 		&exitCode,
 		&errorStr,
 		&durationMs,
+		&steps,
+		&envKeys,
+		&usage,
+		&params,
+		&outputs,
 		&createdAt,
 	)
 
@@ -87,6 +103,21 @@ func (r *SQLiteRepository) GetExecution(ctx context.Context, id string) (*domain
 		duration := durationMs.Int64
 		execution.DurationMs = &duration
 	}
+	if steps.Valid {
+		_ = json.Unmarshal([]byte(steps.String), &execution.Steps)
+	}
+	if envKeys.Valid {
+		_ = json.Unmarshal([]byte(envKeys.String), &execution.EnvKeys)
+	}
+	if usage.Valid {
+		_ = json.Unmarshal([]byte(usage.String), &execution.Usage)
+	}
+	if params.Valid {
+		_ = json.Unmarshal([]byte(params.String), &execution.Params)
+	}
+	if outputs.Valid {
+		_ = json.Unmarshal([]byte(outputs.String), &execution.Outputs)
+	}
 
 	return execution, nil
 }
@@ -94,7 +125,7 @@ func (r *SQLiteRepository) GetExecution(ctx context.Context, id string) (*domain
 // ListExecutions retrieves executions based on filter
 func (r *SQLiteRepository) ListExecutions(ctx context.Context, filter domain.ExecutionFilter) ([]*domain.JobExecution, error) {
 	query := `
-		SELECT e.id, e.job_id, e.started_at, e.completed_at, e.status, e.output, e.exit_code, e.error, e.duration_ms, e.created_at
+		SELECT e.id, e.job_id, e.started_at, e.completed_at, e.status, e.output, e.exit_code, e.error, e.duration_ms, e.steps, e.env_keys, e.usage, e.params, e.outputs, e.created_at
 		FROM job_executions e
 		WHERE 1=1
 	`
@@ -157,7 +188,7 @@ func (r *SQLiteRepository) ListExecutions(ctx context.Context, filter domain.Exe
 		execution := &domain.JobExecution{}
 		var startedAt, createdAt string
 		var completedAt sql.NullString
-		var output, errorStr sql.NullString
+		var output, errorStr, steps, envKeys, usage, params, outputs sql.NullString
 		var exitCode sql.NullInt64
 		var durationMs sql.NullInt64
 
@@ -171,6 +202,11 @@ func (r *SQLiteRepository) ListExecutions(ctx context.Context, filter domain.Exe
 			&exitCode,
 			&errorStr,
 			&durationMs,
+			&steps,
+			&envKeys,
+			&usage,
+			&params,
+			&outputs,
 			&createdAt,
 		)
 		if err != nil {
@@ -198,6 +234,21 @@ func (r *SQLiteRepository) ListExecutions(ctx context.Context, filter domain.Exe
 			duration := durationMs.Int64
 			execution.DurationMs = &duration
 		}
+		if steps.Valid {
+			_ = json.Unmarshal([]byte(steps.String), &execution.Steps)
+		}
+		if envKeys.Valid {
+			_ = json.Unmarshal([]byte(envKeys.String), &execution.EnvKeys)
+		}
+		if usage.Valid {
+			_ = json.Unmarshal([]byte(usage.String), &execution.Usage)
+		}
+		if params.Valid {
+			_ = json.Unmarshal([]byte(params.String), &execution.Params)
+		}
+		if outputs.Valid {
+			_ = json.Unmarshal([]byte(outputs.String), &execution.Outputs)
+		}
 
 		executions = append(executions, execution)
 	}
@@ -253,6 +304,117 @@ func (r *SQLiteRepository) CompleteExecution(ctx context.Context, id string, sta
 	return nil
 }
 
+// FailInterruptedExecutions fails the executions left open by a server that stopped without closing
+// them, including waiting executions of rescheduled jobs, and marks jobs left running as failed.
+// It must only be called before the worker pool starts.
+func (r *SQLiteRepository) FailInterruptedExecutions(ctx context.Context, errorMsg string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE job_executions
+		SET status = ?, error = ?, completed_at = datetime('now', 'utc')
+		WHERE status = ?
+	`, domain.ExecutionStatusFailed, errorMsg, domain.ExecutionStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted executions: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE jobs SET status = ? WHERE status = ?", domain.JobStatusFailed, domain.JobStatusRunning); err != nil {
+		return 0, fmt.Errorf("failed to fail interrupted jobs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	return rows, nil
+}
+
+// SaveExecutionSteps stores the per-step results of an execution
+func (r *SQLiteRepository) SaveExecutionSteps(ctx context.Context, id string, steps []domain.ExecutionStep) error {
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return fmt.Errorf("failed to encode execution steps: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, "UPDATE job_executions SET steps = ? WHERE id = ?", string(data), id)
+	if err != nil {
+		return fmt.Errorf("failed to save execution steps: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrExecutionNotFound
+	}
+
+	return nil
+}
+
+// SaveExecutionEnvKeys stores the names of the environment variables an execution ran with
+func (r *SQLiteRepository) SaveExecutionEnvKeys(ctx context.Context, id string, keys []string) error {
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to encode execution env keys: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, "UPDATE job_executions SET env_keys = ? WHERE id = ?", string(data), id)
+	if err != nil {
+		return fmt.Errorf("failed to save execution env keys: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrExecutionNotFound
+	}
+
+	return nil
+}
+
+// SaveExecutionUsage stores the resources consumed by an execution
+func (r *SQLiteRepository) SaveExecutionUsage(ctx context.Context, id string, usage *domain.ResourceUsage) error {
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return fmt.Errorf("failed to encode execution usage: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, "UPDATE job_executions SET usage = ? WHERE id = ?", string(data), id)
+	if err != nil {
+		return fmt.Errorf("failed to save execution usage: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrExecutionNotFound
+	}
+
+	return nil
+}
+
+// SaveExecutionOutputs stores the named outputs of an execution
+func (r *SQLiteRepository) SaveExecutionOutputs(ctx context.Context, id string, outputs map[string]string) error {
+	data, err := json.Marshal(outputs)
+	if err != nil {
+		return fmt.Errorf("failed to encode execution outputs: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, "UPDATE job_executions SET outputs = ? WHERE id = ?", string(data), id)
+	if err != nil {
+		return fmt.Errorf("failed to save execution outputs: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrExecutionNotFound
+	}
+
+	return nil
+}
+
 // DeleteOldExecutions deletes executions older than the specified date
 func (r *SQLiteRepository) DeleteOldExecutions(ctx context.Context, before time.Time) (int64, error) {
 	result, err := r.db.ExecContext(ctx, "DELETE FROM job_executions WHERE created_at < ?", before.UTC())
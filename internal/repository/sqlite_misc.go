@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -13,25 +14,31 @@ import (
 
 func (r *SQLiteRepository) CreateProject(ctx context.Context, project *domain.Project) error {
 	query := `
-		INSERT INTO projects (name, description, color, icon, is_archived)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO projects (name, description, color, icon, env, is_archived)
+		VALUES (?, ?, ?, ?, ?, ?)
 		RETURNING id, created_at, updated_at
 	`
 
+	env, err := encodeProjectEnv(project.Env)
+	if err != nil {
+		return err
+	}
+
 	return r.db.QueryRowContext(ctx, query,
-		project.Name, project.Description, project.Color, project.Icon, project.IsArchived,
+		project.Name, project.Description, project.Color, project.Icon, env, project.IsArchived,
 	).Scan(&project.ID, &project.CreatedAt, &project.UpdatedAt)
 }
 
 func (r *SQLiteRepository) GetProject(ctx context.Context, id string) (*domain.Project, error) {
-	query := `SELECT id, name, description, color, icon, is_archived, created_at, updated_at FROM projects WHERE id = ?`
+	query := `SELECT id, name, description, color, icon, env, is_archived, created_at, updated_at FROM projects WHERE id = ?`
 
 	project := &domain.Project{}
 	var createdAt, updatedAt string
+	var env sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&project.ID, &project.Name, &project.Description, &project.Color,
-		&project.Icon, &project.IsArchived, &createdAt, &updatedAt,
+		&project.Icon, &env, &project.IsArchived, &createdAt, &updatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -43,12 +50,15 @@ func (r *SQLiteRepository) GetProject(ctx context.Context, id string) (*domain.P
 
 	project.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
 	project.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+	if env.Valid {
+		_ = json.Unmarshal([]byte(env.String), &project.Env)
+	}
 
 	return project, nil
 }
 
 func (r *SQLiteRepository) ListProjects(ctx context.Context, includeArchived bool) ([]*domain.Project, error) {
-	query := "SELECT id, name, description, color, icon, is_archived, created_at, updated_at FROM projects"
+	query := "SELECT id, name, description, color, icon, env, is_archived, created_at, updated_at FROM projects"
 	if !includeArchived {
 		query += " WHERE is_archived = 0"
 	}
@@ -64,10 +74,11 @@ func (r *SQLiteRepository) ListProjects(ctx context.Context, includeArchived boo
 	for rows.Next() {
 		project := &domain.Project{}
 		var createdAt, updatedAt string
+		var env sql.NullString
 
 		err := rows.Scan(
 			&project.ID, &project.Name, &project.Description, &project.Color,
-			&project.Icon, &project.IsArchived, &createdAt, &updatedAt,
+			&project.Icon, &env, &project.IsArchived, &createdAt, &updatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan project: %w", err)
@@ -75,6 +86,9 @@ func (r *SQLiteRepository) ListProjects(ctx context.Context, includeArchived boo
 
 		project.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
 		project.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+		if env.Valid {
+			_ = json.Unmarshal([]byte(env.String), &project.Env)
+		}
 
 		projects = append(projects, project)
 	}
@@ -82,7 +96,7 @@ func (r *SQLiteRepository) ListProjects(ctx context.Context, includeArchived boo
 	return projects, rows.Err()
 }
 
-func (r *SQLiteRepository) UpdateProject(ctx context.Context, id string, name, description, color, icon *string, isArchived *bool) error {
+func (r *SQLiteRepository) UpdateProject(ctx context.Context, id string, name, description, color, icon *string, env map[string]string, isArchived *bool) error {
 	sets := []string{}
 	args := []interface{}{}
 
@@ -102,6 +116,14 @@ func (r *SQLiteRepository) UpdateProject(ctx context.Context, id string, name, d
 		sets = append(sets, "icon = ?")
 		args = append(args, *icon)
 	}
+	if env != nil {
+		encoded, err := encodeProjectEnv(env)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, "env = ?")
+		args = append(args, encoded)
+	}
 	if isArchived != nil {
 		sets = append(sets, "is_archived = ?")
 		args = append(args, *isArchived)
@@ -145,6 +167,18 @@ func (r *SQLiteRepository) DeleteProject(ctx context.Context, id string) error {
 	return nil
 }
 
+// encodeProjectEnv encodes project environment variables for storage, keeping NULL when empty
+func encodeProjectEnv(env map[string]string) (sql.NullString, error) {
+	if len(env) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(env)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode project env: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
 // Tag operations
 
 func (r *SQLiteRepository) CreateTag(ctx context.Context, tag *domain.Tag) error {
@@ -678,7 +712,7 @@ func (r *SQLiteRepository) GetSystemStats(ctx context.Context) (*domain.SystemSt
 
 func (r *SQLiteRepository) GetScheduledJobs(ctx context.Context, before time.Time, limit int) ([]*domain.Job, error) {
 	query := `
-		SELECT id, name, type, config, scheduled_at, priority, project_id, timezone, status, created_at, updated_at
+		SELECT id, name, type, config, scheduled_at, priority, project_id, timezone, status, created_at, updated_at, parameters
 		FROM jobs
 		WHERE status = 'scheduled' AND scheduled_at <= ?
 		ORDER BY priority DESC, scheduled_at ASC
@@ -695,11 +729,12 @@ func (r *SQLiteRepository) GetScheduledJobs(ctx context.Context, before time.Tim
 	for rows.Next() {
 		job := &domain.Job{}
 		var scheduledAt, createdAt, updatedAt string
+		var parameters sql.NullString
 
 		err := rows.Scan(
 			&job.ID, &job.Name, &job.Type, &job.Config, &scheduledAt,
 			&job.Priority, &job.ProjectID, &job.Timezone, &job.Status,
-			&createdAt, &updatedAt,
+			&createdAt, &updatedAt, &parameters,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
@@ -708,6 +743,7 @@ func (r *SQLiteRepository) GetScheduledJobs(ctx context.Context, before time.Tim
 		job.ScheduledAt, _ = time.Parse("2006-01-02 15:04:05", scheduledAt)
 		job.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdAt)
 		job.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedAt)
+		decodeJobParameters(job, parameters)
 
 		jobs = append(jobs, job)
 	}
@@ -729,6 +765,81 @@ func (r *SQLiteRepository) UpdateJobStatus(ctx context.Context, id string, statu
 	return nil
 }
 
+// RescheduleJob moves a job back to the scheduled state at a new time
+func (r *SQLiteRepository) RescheduleJob(ctx context.Context, id string, scheduledAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE jobs SET status = ?, scheduled_at = ? WHERE id = ?",
+		domain.JobStatusScheduled, scheduledAt.UTC(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrJobNotFound
+	}
+
+	return nil
+}
+
+// QueueRun records a run triggered on demand with its parameter values. A job has at most one
+// queued run; domain.ErrRunAlreadyQueued is returned while one is waiting.
+func (r *SQLiteRepository) QueueRun(ctx context.Context, jobID string, params map[string]any) error {
+	var value sql.NullString
+	if len(params) > 0 {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode run parameters: %w", err)
+		}
+		value = sql.NullString{String: string(data), Valid: true}
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO queued_runs (job_id, params) VALUES (?, ?) ON CONFLICT(job_id) DO NOTHING",
+		jobID, value,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to queue run: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrRunAlreadyQueued
+	}
+
+	return nil
+}
+
+// TakeQueuedRun removes the queued run of a job and returns its parameter values, or nil if the
+// job has none
+func (r *SQLiteRepository) TakeQueuedRun(ctx context.Context, jobID string) (map[string]any, error) {
+	var value sql.NullString
+	err := r.db.QueryRowContext(ctx, "DELETE FROM queued_runs WHERE job_id = ? RETURNING params", jobID).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to take queued run: %w", err)
+	}
+
+	params := map[string]any{}
+	if value.Valid {
+		if err := json.Unmarshal([]byte(value.String), &params); err != nil {
+			return nil, fmt.Errorf("failed to decode run parameters: %w", err)
+		}
+	}
+	return params, nil
+}
+
+// DeleteQueuedRun drops the queued run of a job, if any
+func (r *SQLiteRepository) DeleteQueuedRun(ctx context.Context, jobID string) error {
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM queued_runs WHERE job_id = ?", jobID); err != nil {
+		return fmt.Errorf("failed to delete queued run: %w", err)
+	}
+	return nil
+}
+
 // Transaction support
 
 func (r *SQLiteRepository) WithTransaction(ctx context.Context, fn func(Repository) error) error {
@@ -51,9 +51,29 @@ func New(ctx context.Context, cfg *config.Config) (*Server, error) {
 		logging.Warn().Err(err).Msg("Failed to run migrations (continuing anyway)")
 	}
 
+	// Restrict the secrets job configs may reference
+	domain.SetSecretPolicy(domain.SecretPolicy{
+		AllowedEnv: cfg.SecretsAllowedEnv,
+		Dir:        cfg.SecretsDir,
+	})
+
 	// Initialize job registry
 	registry := domain.NewJobRegistry()
-	jobs.RegisterJobTypes(registry)
+	jobs.RegisterJobTypes(registry, jobs.Options{
+		FilesAllowedRoots:        cfg.FilesAllowedRoots,
+		ShellAllowedInterpreters: cfg.ShellAllowedInterpreters,
+		ShellInheritEnv:          cfg.ShellInheritEnv,
+		ShellAllowedRunAs:        cfg.ShellAllowedRunAs,
+		ShellCgroupParent:        cfg.ShellCgroupParent,
+		S3AmbientCredentials:     cfg.S3AmbientCredentials,
+		BackupUploadTargets:      cfg.BackupUploadTargets,
+		DBPath:                   cfg.DBPath,
+	})
+	if cfg.PluginsDir != "" {
+		if err := jobs.LoadPlugins(ctx, registry, cfg.PluginsDir); err != nil {
+			logging.Warn().Err(err).Str("plugins_dir", cfg.PluginsDir).Msg("Failed to load plugins (continuing without them)")
+		}
+	}
 
 	// Initialize worker pool
 	pool := worker.NewPool(cfg.WorkersCount, repo, registry)
@@ -77,6 +97,9 @@ func New(ctx context.Context, cfg *config.Config) (*Server, error) {
 			metricsCollector.IncJobsTotal(jobType, status)
 			metricsCollector.ObserveJobDuration(jobType, duration)
 		})
+		pool.SetUsageCallback(func(jobType, projectID string, usage *domain.ResourceUsage) {
+			metricsCollector.ObserveJobUsage(jobType, projectID, usage)
+		})
 		logging.Info().Msg("Prometheus metrics enabled at /metrics")
 	} else {
 		metricsCollector = &metrics.NoopCollector{}
@@ -87,7 +110,7 @@ func New(ctx context.Context, cfg *config.Config) (*Server, error) {
 	executionService := service.NewExecutionService(repo)
 	projectService := service.NewProjectService(repo)
 	tagService := service.NewTagService(repo)
-	systemService := service.NewSystemService(repo, pool)
+	systemService := service.NewSystemService(repo, pool, registry)
 	apiKeyService := service.NewAPIKeyService(repo)
 	chainService := service.NewChainService(repo, jobService)
 	// Note: webhookService initialized earlier for pool callback
@@ -333,6 +356,14 @@ func setupRoutes(mux *http.ServeMux, h *handler.Handler, apiKeyHandler *handler.
 		}
 	})
 
+	mux.HandleFunc("/api/jobs/validate", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			h.ValidateJob(w, r)
+			return
+		}
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	})
+
 	mux.HandleFunc("/api/jobs/", func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
 		parts := strings.Split(path, "/")
@@ -356,6 +387,16 @@ func setupRoutes(mux *http.ServeMux, h *handler.Handler, apiKeyHandler *handler.
 					h.CancelJob(w, r)
 					return
 				}
+			case "run":
+				if r.Method == http.MethodPost {
+					h.RunJob(w, r)
+					return
+				}
+			case "render":
+				if r.Method == http.MethodPost {
+					h.RenderJobConfig(w, r)
+					return
+				}
 			}
 		}
 
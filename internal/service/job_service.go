@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/meysam81/oneoff/internal/domain"
@@ -10,6 +12,9 @@ import (
 	"github.com/meysam81/oneoff/internal/worker"
 )
 
+// dryRunTimeout bounds the safe checks of a dry run, e.g. pulling a Docker image
+const dryRunTimeout = 5 * time.Minute
+
 // JobService handles business logic for jobs
 type JobService struct {
 	repo     repository.Repository
@@ -28,29 +33,23 @@ func NewJobService(repo repository.Repository, registry *domain.JobRegistry, poo
 
 // CreateJob creates a new job
 func (s *JobService) CreateJob(ctx context.Context, req domain.CreateJobRequest) (*domain.Job, error) {
-	if _, err := s.registry.Create(req.Type, req.Config); err != nil {
+	if err := domain.ValidateConfigTemplate(req.Config); err != nil {
+		return nil, fmt.Errorf("invalid config template: %w", err)
+	}
+	if err := domain.ValidateParameters(req.Parameters); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	config, err := s.renderSampleConfig(ctx, &domain.Job{Name: req.Name, Type: req.Type, Config: req.Config, ProjectID: req.ProjectID, Parameters: req.Parameters})
+	if err != nil {
+		return nil, fmt.Errorf("invalid config template: %w", err)
+	}
+	if err := s.registry.Validate(ctx, req.Type, config); err != nil {
 		return nil, fmt.Errorf("invalid job type or config: %w", err)
 	}
 
-	var scheduledAt time.Time
-
-	if req.Immediate || req.ScheduledAt == "now" {
-		scheduledAt = time.Now().UTC()
-	} else {
-		if req.ScheduledAt == "" {
-			return nil, fmt.Errorf("scheduled_at is required when immediate is false")
-		}
-
-		parsed, err := time.Parse(time.RFC3339, req.ScheduledAt)
-		if err != nil {
-			return nil, fmt.Errorf("invalid scheduled_at format (use RFC3339): %w", err)
-		}
-
-		if parsed.Before(time.Now().UTC()) {
-			return nil, domain.ErrInvalidScheduleTime
-		}
-
-		scheduledAt = parsed.UTC()
+	scheduledAt, err := parseScheduledAt(req)
+	if err != nil {
+		return nil, err
 	}
 
 	priority := req.Priority
@@ -84,6 +83,7 @@ func (s *JobService) CreateJob(ctx context.Context, req domain.CreateJobRequest)
 		ProjectID:   projectID,
 		Timezone:    timezone,
 		Status:      domain.JobStatusScheduled,
+		Parameters:  req.Parameters,
 	}
 
 	if err := s.repo.CreateJob(ctx, job, req.TagIDs); err != nil {
@@ -140,6 +140,36 @@ func (s *JobService) UpdateJob(ctx context.Context, id string, updates domain.Up
 		}
 	}
 
+	if updates.Parameters != nil {
+		if err := domain.ValidateParameters(updates.Parameters); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+
+	// New parameters or a new project change what the config renders to
+	if updates.Config != nil || updates.Parameters != nil || updates.ProjectID != nil {
+		updated := *job
+		if updates.Config != nil {
+			if err := domain.ValidateConfigTemplate(*updates.Config); err != nil {
+				return nil, fmt.Errorf("invalid config template: %w", err)
+			}
+			updated.Config = *updates.Config
+		}
+		if updates.Parameters != nil {
+			updated.Parameters = updates.Parameters
+		}
+		if updates.ProjectID != nil {
+			updated.ProjectID = *updates.ProjectID
+		}
+		config, err := s.renderSampleConfig(ctx, &updated)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config template: %w", err)
+		}
+		if err := s.registry.Validate(ctx, job.Type, config); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
 	if updates.Priority != nil {
 		if *updates.Priority < 1 || *updates.Priority > 10 {
 			return nil, domain.ErrInvalidPriority
@@ -195,19 +225,33 @@ func (s *JobService) CancelJob(ctx context.Context, id string) error {
 		return s.pool.CancelJob(ctx, id)
 	}
 
-	// Otherwise just update status
+	// Otherwise drop a run waiting to start and update status
+	if err := s.repo.DeleteQueuedRun(ctx, id); err != nil {
+		return err
+	}
 	return s.repo.UpdateJobStatus(ctx, id, domain.JobStatusCancelled)
 }
 
-// ExecuteJobNow executes a job immediately
-func (s *JobService) ExecuteJobNow(ctx context.Context, id string) error {
+// ExecuteJobNow executes a job immediately with the given parameter values, returning them with
+// defaults filled in
+func (s *JobService) ExecuteJobNow(ctx context.Context, id string, params map[string]any) (map[string]any, error) {
 	job, err := s.repo.GetJob(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if job.Status == domain.JobStatusRunning {
-		return fmt.Errorf("job is already running")
+		return nil, fmt.Errorf("job is already running")
+	}
+
+	resolved, err := domain.ResolveParams(job.Parameters, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	// The values travel with the queued run, so a second trigger cannot replace them
+	if err := s.repo.QueueRun(ctx, id, params); err != nil {
+		return nil, err
 	}
 
 	// Update scheduled time to now
@@ -217,7 +261,211 @@ func (s *JobService) ExecuteJobNow(ctx context.Context, id string) error {
 		Status:      stringPtr("scheduled"),
 	}
 
-	return s.repo.UpdateJob(ctx, id, updates)
+	if err := s.repo.UpdateJob(ctx, id, updates); err != nil {
+		_ = s.repo.DeleteQueuedRun(ctx, id)
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// RenderJobConfig previews a job's config with its placeholders resolved for a sample execution
+// with the given parameter values. Secrets are masked.
+func (s *JobService) RenderJobConfig(ctx context.Context, id string, params map[string]any) (*domain.RenderedConfig, error) {
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := domain.ResolveParams(job.Parameters, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	var project *domain.Project
+	if job.ProjectID != "" {
+		if project, err = s.repo.GetProject(ctx, job.ProjectID); err != nil {
+			return nil, fmt.Errorf("project not found: %w", err)
+		}
+	}
+
+	data := domain.NewTemplateData(job, project, domain.NewRunContext(job, "preview", 1, resolved))
+	config, err := domain.RenderConfig(job.Config, data, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render config: %w", err)
+	}
+
+	return &domain.RenderedConfig{Template: job.Config, Config: config}, nil
+}
+
+// renderSampleConfig renders a job's config for validation with sample parameter values and
+// secrets masked, so that templated configs are validated like literal ones
+func (s *JobService) renderSampleConfig(ctx context.Context, job *domain.Job) (string, error) {
+	if !strings.Contains(job.Config, "{{") {
+		return job.Config, nil
+	}
+
+	projectID := job.ProjectID
+	if projectID == "" {
+		projectID = "default"
+	}
+	project, err := s.repo.GetProject(ctx, projectID)
+	if err != nil {
+		return "", fmt.Errorf("project not found: %w", err)
+	}
+
+	sample := *job
+	sample.ID = "preview"
+	data := domain.NewTemplateData(&sample, project, domain.NewRunContext(&sample, "preview", 1, domain.SampleParams(job.Parameters)))
+	return domain.RenderConfig(job.Config, data, true)
+}
+
+// ValidateJob checks a job like CreateJob without creating it. The config is rendered with sample
+// parameter values, and the job type's safe checks run against its target when a dry run is asked for.
+func (s *JobService) ValidateJob(ctx context.Context, req domain.ValidateJobRequest) (*domain.JobValidation, error) {
+	result := &domain.JobValidation{}
+	fail := func(field string, err error) {
+		result.Errors = append(result.Errors, domain.FieldError{Field: field, Message: err.Error()})
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		fail("timezone", fmt.Errorf("unknown timezone: %s", timezone))
+	}
+
+	scheduledAt, err := parseScheduledAt(req.CreateJobRequest)
+	if err != nil {
+		fail("scheduled_at", err)
+	} else if location != nil {
+		result.Schedule = &domain.JobSchedule{
+			ScheduledAt: scheduledAt,
+			Local:       scheduledAt.In(location).Format(time.RFC3339),
+			Timezone:    timezone,
+		}
+	}
+
+	if req.Priority != 0 && (req.Priority < 1 || req.Priority > 10) {
+		fail("priority", domain.ErrInvalidPriority)
+	}
+
+	projectID := req.ProjectID
+	if projectID == "" {
+		projectID = "default"
+	}
+	project, err := s.repo.GetProject(ctx, projectID)
+	if errors.Is(err, domain.ErrProjectNotFound) {
+		fail("project_id", domain.ErrProjectNotFound)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	// The config can only be rendered when its placeholders parse and the sample values fit the parameters
+	renderable := true
+	if err := domain.ValidateConfigTemplate(req.Config); err != nil {
+		fail("config", fmt.Errorf("invalid config template: %w", err))
+		renderable = false
+	}
+	var params map[string]any
+	if err := domain.ValidateParameters(req.Parameters); err != nil {
+		fail("parameters", err)
+		renderable = false
+	} else if params, err = domain.ResolveParams(req.Parameters, req.Params); err != nil {
+		fail("params", err)
+		renderable = false
+	}
+
+	job := &domain.Job{
+		ID:          "preview",
+		Name:        req.Name,
+		Type:        req.Type,
+		Config:      req.Config,
+		ScheduledAt: scheduledAt,
+		ProjectID:   projectID,
+		Timezone:    timezone,
+		Parameters:  req.Parameters,
+	}
+	runCtx := domain.NewRunContext(job, "preview", 1, params)
+	data := domain.NewTemplateData(job, project, runCtx)
+
+	// Validate the config with secrets masked; they are only resolved for the dry run
+	config := req.Config
+	rendered := false
+	if renderable {
+		if config, err = domain.RenderConfig(req.Config, data, true); err != nil {
+			fail("config", fmt.Errorf("failed to render config: %w", err))
+		} else {
+			result.RenderedConfig = config
+			rendered = true
+		}
+	}
+
+	// A config whose placeholders cannot be rendered has already failed and is not validated further
+	if rendered || !strings.Contains(req.Config, "{{") {
+		if err := s.registry.Validate(ctx, req.Type, config); err != nil {
+			var validationErr *domain.ValidationError
+			if !errors.As(err, &validationErr) {
+				return nil, err
+			}
+			for _, fieldErr := range validationErr.Errors {
+				if fieldErr.Policy {
+					result.Violations = append(result.Violations, fieldErr.Message)
+					continue
+				}
+				result.Errors = append(result.Errors, fieldErr)
+			}
+		} else if executor, err := s.registry.Create(req.Type, config); err == nil {
+			result.Description = executor.Description()
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0 && len(result.Violations) == 0
+	if req.DryRun && result.Valid {
+		result.DryRun = s.dryRun(ctx, req.Type, req.Config, data, runCtx)
+		result.Valid = !result.DryRun.Supported || result.DryRun.Passed
+	}
+
+	return result, nil
+}
+
+// dryRun renders the config with its secrets and runs the job type's safe checks, if it has any
+func (s *JobService) dryRun(ctx context.Context, jobType, template string, data *domain.TemplateData, runCtx *domain.RunContext) *domain.DryRunResult {
+	result := &domain.DryRunResult{}
+
+	config, err := domain.RenderConfig(template, data, false)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to render config: %v", err)
+		return result
+	}
+	executor, err := s.registry.Create(jobType, config)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if aware, ok := executor.(domain.RunContextAware); ok {
+		aware.SetRunContext(runCtx)
+	}
+
+	runner, ok := executor.(domain.DryRunner)
+	if !ok {
+		return result
+	}
+	result.Supported = true
+
+	ctx, cancel := context.WithTimeout(ctx, dryRunTimeout)
+	defer cancel()
+
+	outcome, err := runner.DryRun(ctx)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Passed = outcome.ExitCode == 0
+	result.Output = outcome.Output
+	result.Error = outcome.Error
+	return result
 }
 
 // CloneJob creates a copy of an existing job
@@ -241,6 +489,7 @@ func (s *JobService) CloneJob(ctx context.Context, id string, newScheduledAt tim
 		ProjectID:   original.ProjectID,
 		Timezone:    original.Timezone,
 		TagIDs:      tagIDs,
+		Parameters:  original.Parameters,
 	}
 
 	return s.CreateJob(ctx, req)
@@ -249,3 +498,22 @@ func (s *JobService) CloneJob(ctx context.Context, id string, newScheduledAt tim
 func stringPtr(s string) *string {
 	return &s
 }
+
+// parseScheduledAt returns when a job being created should run
+func parseScheduledAt(req domain.CreateJobRequest) (time.Time, error) {
+	if req.Immediate || req.ScheduledAt == "now" {
+		return time.Now().UTC(), nil
+	}
+	if req.ScheduledAt == "" {
+		return time.Time{}, fmt.Errorf("scheduled_at is required when immediate is false")
+	}
+
+	parsed, err := time.Parse(time.RFC3339, req.ScheduledAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid scheduled_at format (use RFC3339): %w", err)
+	}
+	if parsed.Before(time.Now().UTC()) {
+		return time.Time{}, domain.ErrInvalidScheduleTime
+	}
+	return parsed.UTC(), nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/meysam81/oneoff/internal/domain"
 	"github.com/meysam81/oneoff/internal/repository"
@@ -18,16 +19,20 @@ func NewProjectService(repo repository.Repository) *ProjectService {
 }
 
 // CreateProject creates a new project
-func (s *ProjectService) CreateProject(ctx context.Context, name, description, color, icon string) (*domain.Project, error) {
+func (s *ProjectService) CreateProject(ctx context.Context, name, description, color, icon string, env map[string]string) (*domain.Project, error) {
 	if name == "" {
 		return nil, domain.ErrMissingRequiredField
 	}
+	if err := validateProjectEnv(env); err != nil {
+		return nil, err
+	}
 
 	project := &domain.Project{
 		Name:        name,
 		Description: description,
 		Color:       color,
 		Icon:        icon,
+		Env:         env,
 		IsArchived:  false,
 	}
 
@@ -49,8 +54,11 @@ func (s *ProjectService) ListProjects(ctx context.Context, includeArchived bool)
 }
 
 // UpdateProject updates a project
-func (s *ProjectService) UpdateProject(ctx context.Context, id string, name, description, color, icon *string, isArchived *bool) (*domain.Project, error) {
-	if err := s.repo.UpdateProject(ctx, id, name, description, color, icon, isArchived); err != nil {
+func (s *ProjectService) UpdateProject(ctx context.Context, id string, name, description, color, icon *string, env map[string]string, isArchived *bool) (*domain.Project, error) {
+	if err := validateProjectEnv(env); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateProject(ctx, id, name, description, color, icon, env, isArchived); err != nil {
 		return nil, err
 	}
 	return s.repo.GetProject(ctx, id)
@@ -60,3 +68,13 @@ func (s *ProjectService) UpdateProject(ctx context.Context, id string, name, des
 func (s *ProjectService) DeleteProject(ctx context.Context, id string) error {
 	return s.repo.DeleteProject(ctx, id)
 }
+
+// validateProjectEnv checks that project environment variable names are valid
+func validateProjectEnv(env map[string]string) error {
+	for key := range env {
+		if !domain.IsValidEnvName(key) {
+			return fmt.Errorf("invalid environment variable name: %q", key)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"sort"
 
 	"github.com/meysam81/oneoff/internal/domain"
 	"github.com/meysam81/oneoff/internal/repository"
@@ -10,15 +11,17 @@ import (
 
 // SystemService handles business logic for system operations
 type SystemService struct {
-	repo repository.Repository
-	pool *worker.Pool
+	repo     repository.Repository
+	pool     *worker.Pool
+	registry *domain.JobRegistry
 }
 
 // NewSystemService creates a new system service
-func NewSystemService(repo repository.Repository, pool *worker.Pool) *SystemService {
+func NewSystemService(repo repository.Repository, pool *worker.Pool, registry *domain.JobRegistry) *SystemService {
 	return &SystemService{
-		repo: repo,
-		pool: pool,
+		repo:     repo,
+		pool:     pool,
+		registry: registry,
 	}
 }
 
@@ -42,8 +45,14 @@ func (s *SystemService) UpdateConfig(ctx context.Context, key, value string) err
 	return s.repo.SetConfig(ctx, key, value)
 }
 
-// GetJobTypes retrieves all available job types
-func (s *SystemService) GetJobTypes() []string {
-	// This would come from the registry
-	return []string{"http", "shell", "docker"}
+// GetJobTypes retrieves all available job types, including plugin-provided ones, with their config schemas
+func (s *SystemService) GetJobTypes() []domain.JobTypeInfo {
+	types := s.registry.ListTypes()
+	sort.Strings(types)
+
+	infos := make([]domain.JobTypeInfo, len(types))
+	for i, jobType := range types {
+		infos[i] = s.registry.TypeInfo(jobType)
+	}
+	return infos
 }
@@ -17,6 +17,9 @@ type JobEventCallback func(ctx context.Context, event domain.WebhookEvent)
 // MetricsCallback is called to report job metrics
 type MetricsCallback func(jobType, status string, duration time.Duration)
 
+// UsageCallback is called to report the resources consumed by a job execution
+type UsageCallback func(jobType, projectID string, usage *domain.ResourceUsage)
+
 // Pool manages a pool of workers for executing jobs
 type Pool struct {
 	workers          int
@@ -29,10 +32,22 @@ type Pool struct {
 	jobContexts      map[string]context.CancelFunc // Track cancel functions for running jobs
 	runningMutex     sync.RWMutex
 	pollInterval     time.Duration
-	logRetentionDays int              // Days to retain execution logs (0 = no cleanup)
-	cleanupInterval  time.Duration    // How often to run cleanup
-	onJobEvent       JobEventCallback // Callback for job events (webhooks)
-	onMetrics        MetricsCallback  // Callback for metrics
+	logRetentionDays int                          // Days to retain execution logs (0 = no cleanup)
+	cleanupInterval  time.Duration                // How often to run cleanup
+	onJobEvent       JobEventCallback             // Callback for job events (webhooks)
+	onMetrics        MetricsCallback              // Callback for metrics
+	onUsage          UsageCallback                // Callback for resource usage metrics
+	waiting          map[string]*waitingExecution // Executions of rescheduled jobs, keyed by job ID
+	waitingMutex     sync.Mutex
+}
+
+// waitingExecution is an execution whose job asked to be rescheduled (e.g. a sensor
+// that is not satisfied yet). It stays open across runs without holding a worker.
+type waitingExecution struct {
+	execution *domain.JobExecution
+	startTime time.Time
+	output    string
+	attempt   int
 }
 
 // NewPool creates a new worker pool
@@ -45,6 +60,7 @@ func NewPool(workers int, repo repository.Repository, registry *domain.JobRegist
 		stopChan:         make(chan struct{}),
 		runningJobs:      make(map[string]bool),
 		jobContexts:      make(map[string]context.CancelFunc),
+		waiting:          make(map[string]*waitingExecution),
 		pollInterval:     5 * time.Second, // Check for new jobs every 5 seconds
 		logRetentionDays: 90,              // Default: 90 days
 		cleanupInterval:  24 * time.Hour,  // Run cleanup daily
@@ -73,6 +89,18 @@ func (p *Pool) reportMetrics(jobType, status string, duration time.Duration) {
 	}
 }
 
+// SetUsageCallback sets the callback for resource usage metrics
+func (p *Pool) SetUsageCallback(callback UsageCallback) {
+	p.onUsage = callback
+}
+
+// reportUsage reports the resources consumed by a job execution
+func (p *Pool) reportUsage(job *domain.Job, usage *domain.ResourceUsage) {
+	if p.onUsage != nil && usage != nil {
+		p.onUsage(job.Type, job.ProjectID, usage)
+	}
+}
+
 // emitJobEvent emits a job event via the callback
 func (p *Pool) emitJobEvent(ctx context.Context, eventType domain.WebhookEventType, job *domain.Job, execution *domain.JobExecution) {
 	if p.onJobEvent != nil {
@@ -91,6 +119,16 @@ func (p *Pool) emitJobEvent(ctx context.Context, eventType domain.WebhookEventTy
 func (p *Pool) Start(ctx context.Context) error {
 	logging.Info().Int("workers", p.workers).Msg("Starting worker pool")
 
+	// Nothing runs yet, so open executions were interrupted by the last shutdown. Waiting
+	// executions are only tracked in memory; their jobs stay scheduled and start a new one.
+	recovered, err := p.repo.FailInterruptedExecutions(ctx, "Server stopped while the job was running or waiting")
+	if err != nil {
+		return fmt.Errorf("failed to recover interrupted executions: %w", err)
+	}
+	if recovered > 0 {
+		logging.Warn().Int64("count", recovered).Msg("Failed executions interrupted by the last shutdown")
+	}
+
 	// Start workers
 	for i := 0; i < p.workers; i++ {
 		p.wg.Add(1)
@@ -128,6 +166,7 @@ func (p *Pool) Stop(ctx context.Context) error {
 
 	select {
 	case <-done:
+		p.closeWaitingExecutions(ctx)
 		logging.Info().Msg("Worker pool stopped gracefully")
 		return nil
 	case <-ctx.Done():
@@ -249,7 +288,20 @@ func (p *Pool) executeJob(ctx context.Context, job *domain.Job) {
 		p.runningMutex.Unlock()
 	}()
 
+	// Resume the open execution of a rescheduled job, or start a new one
+	p.waitingMutex.Lock()
+	waiting := p.waiting[job.ID]
+	delete(p.waiting, job.ID)
+	p.waitingMutex.Unlock()
+
 	startTime := time.Now()
+	priorOutput := ""
+	attempt := 1
+	if waiting != nil {
+		startTime = waiting.startTime
+		priorOutput = waiting.output
+		attempt = waiting.attempt + 1
+	}
 
 	// Update job status to running
 	if err := p.repo.UpdateJobStatus(ctx, job.ID, domain.JobStatusRunning); err != nil {
@@ -257,41 +309,99 @@ func (p *Pool) executeJob(ctx context.Context, job *domain.Job) {
 		return
 	}
 
-	// Create execution record
-	execution := &domain.JobExecution{
-		JobID:     job.ID,
-		StartedAt: startTime,
-		Status:    domain.ExecutionStatusRunning,
+	var execution *domain.JobExecution
+	var paramsErr error
+	if waiting != nil {
+		execution = waiting.execution
+	} else {
+		// Values given when the run was triggered apply to this run only
+		runParams, err := p.repo.TakeQueuedRun(ctx, job.ID)
+		if err != nil {
+			logging.Error().Err(err).Str("job_id", job.ID).Msg("Failed to take queued run")
+			return
+		}
+		params, err := domain.ResolveParams(job.Parameters, runParams)
+		paramsErr = err
+
+		// Create execution record
+		execution = &domain.JobExecution{
+			JobID:     job.ID,
+			StartedAt: startTime,
+			Status:    domain.ExecutionStatusRunning,
+			Params:    params,
+		}
+
+		if err := p.repo.CreateExecution(ctx, execution); err != nil {
+			logging.Error().Err(err).Str("job_id", job.ID).Msg("Failed to create execution record")
+			return
+		}
+
+		// Emit job started event
+		p.emitJobEvent(ctx, domain.WebhookEventJobStarted, job, execution)
 	}
 
-	if err := p.repo.CreateExecution(ctx, execution); err != nil {
-		logging.Error().Err(err).Str("job_id", job.ID).Msg("Failed to create execution record")
+	if paramsErr != nil {
+		logging.Error().Err(paramsErr).Str("job_id", job.ID).Msg("Invalid job parameters")
+		p.failExecution(ctx, job, execution, fmt.Sprintf("Invalid parameters: %v", paramsErr), priorOutput, startTime)
 		return
 	}
 
-	// Emit job started event
-	p.emitJobEvent(ctx, domain.WebhookEventJobStarted, job, execution)
+	// The project provides variables to config templates and to executors that inject environments
+	var project *domain.Project
+	if job.ProjectID != "" {
+		loaded, err := p.repo.GetProject(ctx, job.ProjectID)
+		if err != nil {
+			logging.Warn().Err(err).Str("job_id", job.ID).Str("project_id", job.ProjectID).Msg("Failed to load project")
+		} else {
+			project = loaded
+		}
+	}
+	runCtx := domain.NewRunContext(job, execution.ID, attempt, execution.Params)
+
+	// Resolve the placeholders of the config; the stored config keeps them
+	config, err := domain.RenderConfig(job.Config, domain.NewTemplateData(job, project, runCtx), false)
+	if err != nil {
+		logging.Error().Err(err).Str("job_id", job.ID).Msg("Failed to render job config")
+		p.failExecution(ctx, job, execution, fmt.Sprintf("Failed to render config: %v", err), priorOutput, startTime)
+		return
+	}
 
 	// Create job executor
-	executor, err := p.registry.Create(job.Type, job.Config)
+	executor, err := p.registry.Create(job.Type, config)
 	if err != nil {
 		logging.Error().Err(err).
 			Str("job_id", job.ID).
 			Str("job_type", job.Type).
 			Msg("Failed to create job executor")
 
-		p.completeExecution(ctx, execution.ID, job.ID, domain.ExecutionStatusFailed, "", fmt.Sprintf("Failed to create executor: %v", err), nil, time.Since(startTime))
+		p.completeExecution(ctx, execution.ID, job.ID, domain.ExecutionStatusFailed, priorOutput, fmt.Sprintf("Failed to create executor: %v", err), nil, time.Since(startTime))
 		return
 	}
 
+	// Executors that forward the execution's identity to what they run get it before executing
+	if aware, ok := executor.(domain.RunContextAware); ok {
+		aware.SetRunContext(runCtx)
+	}
+
+	execCtx := domain.WithFirstAttempt(jobCtx, startTime)
+	if project != nil && len(project.Env) > 0 {
+		execCtx = domain.WithProjectEnv(execCtx, project.Env)
+	}
+
 	// Execute job with cancellable context
-	result, err := executor.Execute(jobCtx)
+	result, err := executor.Execute(execCtx)
 
-	// Check if the job was cancelled
+	// Check if the job was cancelled; what it reported before stopping is kept
 	if jobCtx.Err() == context.Canceled {
 		logging.Info().Str("job_id", job.ID).Msg("Job was cancelled")
 		execution.Status = domain.ExecutionStatusCancelled
-		p.completeExecution(ctx, execution.ID, job.ID, domain.ExecutionStatusCancelled, "", "Job cancelled by user", nil, time.Since(startTime))
+		output := priorOutput
+		if result != nil {
+			output += result.Output
+			p.saveResultDetails(ctx, execution, result)
+			p.reportUsage(job, result.Usage)
+		}
+		p.completeExecution(ctx, execution.ID, job.ID, domain.ExecutionStatusCancelled, output, "Job cancelled by user", nil, time.Since(startTime))
 		// Emit cancelled event
 		p.emitJobEvent(ctx, domain.WebhookEventJobCancelled, job, execution)
 		// Report metrics
@@ -305,7 +415,7 @@ func (p *Pool) executeJob(ctx context.Context, job *domain.Job) {
 
 		execution.Status = domain.ExecutionStatusFailed
 		execution.Error = fmt.Sprintf("Execution error: %v", err)
-		p.completeExecution(ctx, execution.ID, job.ID, domain.ExecutionStatusFailed, "", fmt.Sprintf("Execution error: %v", err), nil, time.Since(startTime))
+		p.completeExecution(ctx, execution.ID, job.ID, domain.ExecutionStatusFailed, priorOutput, fmt.Sprintf("Execution error: %v", err), nil, time.Since(startTime))
 		// Update job status to failed
 		if updateErr := p.repo.UpdateJobStatus(ctx, job.ID, domain.JobStatusFailed); updateErr != nil {
 			logging.Error().Err(updateErr).Str("job_id", job.ID).Msg("Failed to update job status to failed")
@@ -317,6 +427,18 @@ func (p *Pool) executeJob(ctx context.Context, job *domain.Job) {
 		return
 	}
 
+	// The job asked to run again later; keep the execution open and free the worker
+	if result.RescheduleAfter > 0 {
+		p.rescheduleJob(ctx, job, &waitingExecution{
+			execution: execution,
+			startTime: startTime,
+			output:    priorOutput + result.Output,
+			attempt:   attempt,
+		}, result.RescheduleAfter)
+		return
+	}
+	result.Output = priorOutput + result.Output
+
 	// Determine final status
 	finalStatus := domain.ExecutionStatusCompleted
 	finalJobStatus := domain.JobStatusCompleted
@@ -333,6 +455,7 @@ func (p *Pool) executeJob(ctx context.Context, job *domain.Job) {
 	execution.Output = result.Output
 	execution.Error = result.Error
 	execution.ExitCode = &result.ExitCode
+	p.saveResultDetails(ctx, execution, result)
 	p.completeExecution(ctx, execution.ID, job.ID, finalStatus, result.Output, result.Error, &result.ExitCode, time.Since(startTime))
 
 	// Update job status
@@ -345,6 +468,7 @@ func (p *Pool) executeJob(ctx context.Context, job *domain.Job) {
 
 	// Report metrics
 	p.reportMetrics(job.Type, string(finalStatus), time.Since(startTime))
+	p.reportUsage(job, result.Usage)
 
 	logging.Info().
 		Str("job_id", job.ID).
@@ -355,6 +479,86 @@ func (p *Pool) executeJob(ctx context.Context, job *domain.Job) {
 		Msg("Job execution completed")
 }
 
+// saveResultDetails records the steps, environment, usage and outputs of a result on the execution
+func (p *Pool) saveResultDetails(ctx context.Context, execution *domain.JobExecution, result *domain.ExecutionResult) {
+	execution.Steps = result.Steps
+	execution.EnvKeys = result.EnvKeys
+	execution.Usage = result.Usage
+	execution.Outputs = result.Outputs
+	if len(result.Steps) > 0 {
+		if err := p.repo.SaveExecutionSteps(ctx, execution.ID, result.Steps); err != nil {
+			logging.Error().Err(err).Str("execution_id", execution.ID).Msg("Failed to save execution steps")
+		}
+	}
+	if len(result.EnvKeys) > 0 {
+		if err := p.repo.SaveExecutionEnvKeys(ctx, execution.ID, result.EnvKeys); err != nil {
+			logging.Error().Err(err).Str("execution_id", execution.ID).Msg("Failed to save execution env keys")
+		}
+	}
+	if result.Usage != nil {
+		if err := p.repo.SaveExecutionUsage(ctx, execution.ID, result.Usage); err != nil {
+			logging.Error().Err(err).Str("execution_id", execution.ID).Msg("Failed to save execution usage")
+		}
+	}
+	if len(result.Outputs) > 0 {
+		if err := p.repo.SaveExecutionOutputs(ctx, execution.ID, result.Outputs); err != nil {
+			logging.Error().Err(err).Str("execution_id", execution.ID).Msg("Failed to save execution outputs")
+		}
+	}
+}
+
+// rescheduleJob parks an open execution until the job's next run
+func (p *Pool) rescheduleJob(ctx context.Context, job *domain.Job, waiting *waitingExecution, after time.Duration) {
+	if err := p.repo.UpdateExecution(ctx, waiting.execution.ID, domain.ExecutionStatusRunning, waiting.output, "", nil); err != nil {
+		logging.Error().Err(err).Str("job_id", job.ID).Msg("Failed to update execution output")
+	}
+
+	p.waitingMutex.Lock()
+	p.waiting[job.ID] = waiting
+	p.waitingMutex.Unlock()
+
+	if err := p.repo.RescheduleJob(ctx, job.ID, time.Now().Add(after)); err != nil {
+		logging.Error().Err(err).Str("job_id", job.ID).Msg("Failed to reschedule job")
+		p.waitingMutex.Lock()
+		delete(p.waiting, job.ID)
+		p.waitingMutex.Unlock()
+		p.completeExecution(ctx, waiting.execution.ID, job.ID, domain.ExecutionStatusFailed, waiting.output, fmt.Sprintf("Failed to reschedule job: %v", err), nil, time.Since(waiting.startTime))
+		if updateErr := p.repo.UpdateJobStatus(ctx, job.ID, domain.JobStatusFailed); updateErr != nil {
+			logging.Error().Err(updateErr).Str("job_id", job.ID).Msg("Failed to update job status to failed")
+		}
+		return
+	}
+
+	logging.Debug().
+		Str("job_id", job.ID).
+		Dur("after", after).
+		Msg("Job rescheduled, execution kept open")
+}
+
+// closeWaitingExecutions fails executions that are between runs on shutdown. Their jobs stay
+// scheduled and start a new execution once the server is back.
+func (p *Pool) closeWaitingExecutions(ctx context.Context) {
+	p.waitingMutex.Lock()
+	defer p.waitingMutex.Unlock()
+
+	for jobID, waiting := range p.waiting {
+		p.completeExecution(ctx, waiting.execution.ID, jobID, domain.ExecutionStatusFailed, waiting.output, "Server stopped while the job was waiting", nil, time.Since(waiting.startTime))
+		delete(p.waiting, jobID)
+	}
+}
+
+// failExecution fails an execution that could not be started and marks its job failed
+func (p *Pool) failExecution(ctx context.Context, job *domain.Job, execution *domain.JobExecution, errorMsg, output string, startTime time.Time) {
+	execution.Status = domain.ExecutionStatusFailed
+	execution.Error = errorMsg
+	p.completeExecution(ctx, execution.ID, job.ID, domain.ExecutionStatusFailed, output, errorMsg, nil, time.Since(startTime))
+	if err := p.repo.UpdateJobStatus(ctx, job.ID, domain.JobStatusFailed); err != nil {
+		logging.Error().Err(err).Str("job_id", job.ID).Msg("Failed to update job status to failed")
+	}
+	p.emitJobEvent(ctx, domain.WebhookEventJobFailed, job, execution)
+	p.reportMetrics(job.Type, string(domain.ExecutionStatusFailed), time.Since(startTime))
+}
+
 // completeExecution marks an execution as complete
 func (p *Pool) completeExecution(ctx context.Context, executionID, jobID string, status domain.ExecutionStatus, output, errorMsg string, exitCode *int, duration time.Duration) {
 	durationMs := duration.Milliseconds()
@@ -400,10 +604,24 @@ func (p *Pool) CancelJob(ctx context.Context, jobID string) error {
 		return fmt.Errorf("failed to update job status: %w", err)
 	}
 
+	p.waitingMutex.Lock()
+	waiting := p.waiting[jobID]
+	delete(p.waiting, jobID)
+	p.waitingMutex.Unlock()
+
 	if isRunning && cancelFunc != nil {
 		// Cancel the job's context - this will trigger cancellation in the executor
 		cancelFunc()
 		logging.Info().Str("job_id", jobID).Msg("Job cancellation signal sent")
+	} else if waiting != nil {
+		// The job is between runs, so close its open execution directly
+		p.completeExecution(ctx, waiting.execution.ID, jobID, domain.ExecutionStatusCancelled, waiting.output, "Job cancelled by user", nil, time.Since(waiting.startTime))
+		waiting.execution.Status = domain.ExecutionStatusCancelled
+		if job, err := p.repo.GetJob(ctx, jobID); err == nil {
+			p.emitJobEvent(ctx, domain.WebhookEventJobCancelled, job, waiting.execution)
+			p.reportMetrics(job.Type, string(domain.ExecutionStatusCancelled), time.Since(waiting.startTime))
+		}
+		logging.Info().Str("job_id", jobID).Msg("Waiting job cancelled")
 	} else {
 		logging.Debug().Str("job_id", jobID).Bool("is_running", isRunning).Msg("Job not currently running, status updated")
 	}
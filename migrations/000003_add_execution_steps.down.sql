@@ -0,0 +1,2 @@
+-- Drop per-step results
+ALTER TABLE job_executions DROP COLUMN steps;
@@ -0,0 +1,2 @@
+-- Per-step results of multi-step jobs, stored as JSON
+ALTER TABLE job_executions ADD COLUMN steps TEXT;
@@ -0,0 +1,5 @@
+-- Drop recorded environment variable names
+ALTER TABLE job_executions DROP COLUMN env_keys;
+
+-- Drop project environment variables
+ALTER TABLE projects DROP COLUMN env;
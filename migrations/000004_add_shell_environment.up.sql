@@ -0,0 +1,5 @@
+-- Environment variables injected into the shell jobs of a project, stored as JSON
+ALTER TABLE projects ADD COLUMN env TEXT;
+
+-- Names of the environment variables an execution ran with, stored as JSON
+ALTER TABLE job_executions ADD COLUMN env_keys TEXT;
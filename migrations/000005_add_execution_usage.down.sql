@@ -0,0 +1,2 @@
+-- Drop recorded resource usage
+ALTER TABLE job_executions DROP COLUMN usage;
@@ -0,0 +1,2 @@
+-- Resources consumed by an execution, stored as JSON
+ALTER TABLE job_executions ADD COLUMN usage TEXT;
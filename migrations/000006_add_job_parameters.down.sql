@@ -0,0 +1,6 @@
+-- Drop execution parameter values
+ALTER TABLE job_executions DROP COLUMN params;
+
+-- Drop job parameters
+ALTER TABLE jobs DROP COLUMN run_params;
+ALTER TABLE jobs DROP COLUMN parameters;
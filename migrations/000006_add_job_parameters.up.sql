@@ -0,0 +1,8 @@
+-- Parameters declared by a job, stored as JSON
+ALTER TABLE jobs ADD COLUMN parameters TEXT;
+
+-- Parameter values for the next run of a job, stored as JSON
+ALTER TABLE jobs ADD COLUMN run_params TEXT;
+
+-- Parameter values an execution ran with, stored as JSON
+ALTER TABLE job_executions ADD COLUMN params TEXT;
@@ -0,0 +1,2 @@
+-- Drop recorded execution outputs
+ALTER TABLE job_executions DROP COLUMN outputs;
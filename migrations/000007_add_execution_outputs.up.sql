@@ -0,0 +1,2 @@
+-- Named outputs emitted by an execution, stored as JSON
+ALTER TABLE job_executions ADD COLUMN outputs TEXT;
@@ -0,0 +1,5 @@
+-- Restore the per-job run parameters
+ALTER TABLE jobs ADD COLUMN run_params TEXT;
+
+-- Drop queued runs
+DROP TABLE IF EXISTS queued_runs;
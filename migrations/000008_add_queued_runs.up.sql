@@ -0,0 +1,10 @@
+-- Runs triggered on demand that wait for a worker, with the parameter values they were triggered with
+CREATE TABLE IF NOT EXISTS queued_runs (
+    job_id TEXT PRIMARY KEY,
+    params TEXT, -- stored as JSON
+    created_at DATETIME NOT NULL DEFAULT (datetime('now', 'utc')),
+    FOREIGN KEY (job_id) REFERENCES jobs(id) ON DELETE CASCADE
+);
+
+-- Run parameters now travel with the queued run instead of the job
+ALTER TABLE jobs DROP COLUMN run_params;
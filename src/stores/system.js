@@ -6,7 +6,7 @@ import { Cache, dedupe } from "../utils/cache";
 const CACHE_KEYS = {
   PROJECTS: "projects",
   TAGS: "tags",
-  JOB_TYPES: "job_types",
+  JOB_TYPES: "job_types_v2",
   STATS: "stats",
   WORKER_STATUS: "worker_status",
 };